@@ -0,0 +1,90 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/snasphysicist/ferp/v2/pkg/configuration"
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+)
+
+// dropPrivileges switches the process to the configured user & group, if any,
+// then logs an error for each file the proxy reads again later which it can no longer read
+func dropPrivileges(c configuration.Configuration, l log.Logger) error {
+	ra := c.RunAs
+	if ra.User == "" {
+		return nil
+	}
+	// e.g. after an upgrade by a process which had already dropped privileges
+	if os.Geteuid() == ra.UID && os.Getegid() == ra.GID {
+		l.Infof("Already running as user %s (%d) and group %d", ra.User, ra.UID, ra.GID)
+		return nil
+	}
+	if err := syscall.Setgroups([]int{ra.GID}); err != nil {
+		return fmt.Errorf("failed to set supplementary groups to %d: %w", ra.GID, err)
+	}
+	if err := syscall.Setgid(ra.GID); err != nil {
+		return fmt.Errorf("failed to set group to %d: %w", ra.GID, err)
+	}
+	if err := syscall.Setuid(ra.UID); err != nil {
+		return fmt.Errorf("failed to set user to %s (%d): %w", ra.User, ra.UID, err)
+	}
+	l.Infof("Dropped privileges to user %s (%d) and group %d", ra.User, ra.UID, ra.GID)
+	for _, f := range rereadFiles(c) {
+		if err := canOpen(f.path, f.flag, l); err != nil {
+			l.Errorf("After dropping privileges to user %s, %s cannot be opened, so %s: %s",
+				ra.User, f.path, f.consequence, err)
+		}
+	}
+	return nil
+}
+
+// rereadFile is a file the proxy reads (or appends to) again after starting,
+// with what goes wrong if it cannot be opened then
+type rereadFile struct {
+	path        string
+	flag        int // to open the file with
+	consequence string
+}
+
+// rereadFiles lists the files the proxy reads (or opens) again after starting:
+// certificates and the access & application logs by the new process on upgrade,
+// and redirect maps whenever they change
+func rereadFiles(c configuration.Configuration) []rereadFile {
+	fs := make([]rereadFile, 0)
+	if c.AccessLog.Format != "" && c.AccessLog.Output != configuration.AccessLogStdout {
+		fs = append(fs, rereadFile{path: c.AccessLog.Output, flag: os.O_WRONLY | os.O_APPEND,
+			consequence: "upgrades (SIGUSR2) will fail to open it"})
+	}
+	for _, o := range c.Logging.Outputs {
+		if o != "stdout" && o != "stderr" {
+			fs = append(fs, rereadFile{path: o, flag: os.O_WRONLY | os.O_APPEND,
+				consequence: "upgrades (SIGUSR2) will fail to open it"})
+		}
+	}
+	for _, s := range c.Servers {
+		if !configuration.Serves(s) {
+			continue
+		}
+		if s.TLS.CertFile != "" {
+			const upgradeFails = "upgrades (SIGUSR2) will fail to load certificates"
+			fs = append(fs, rereadFile{path: s.TLS.CertFile, consequence: upgradeFails},
+				rereadFile{path: s.TLS.KeyFile, consequence: upgradeFails})
+		}
+		for _, rm := range s.RedirectMaps {
+			fs = append(fs, rereadFile{path: rm.File, consequence: "changes to the redirect map will not be loaded"})
+		}
+	}
+	return fs
+}
+
+// canOpen returns an error if the file cannot be opened with the flag (e.g. read only)
+func canOpen(path string, flag int, l log.Logger) error {
+	f, err := os.OpenFile(path, flag, 0)
+	if err != nil {
+		return err
+	}
+	closeLoggingErrors(f, l)
+	return nil
+}
@@ -3,12 +3,19 @@ package command
 import (
 	"github.com/snasphysicist/ferp/v2/pkg/configuration"
 	"github.com/snasphysicist/ferp/v2/pkg/log"
+	"github.com/snasphysicist/ferp/v2/pkg/mapper"
 	"github.com/spf13/cobra"
+	"go.uber.org/zap/zapcore"
 )
 
 // Execute is the main entry point for the whole application
 func Execute() {
-	defer initialiseLogging()()
+	var l log.Logger = buildLogger(log.Options{Level: zapcore.InfoLevel, Encoding: configuration.LogJSON}, log.Nop())
+	defer func() {
+		if r, ok := l.(*log.Root); ok {
+			r.Sync()
+		}
+	}()
 	root := cobra.Command{
 		Use:   "ferp",
 		Short: "fabulously easy reverse proxy",
@@ -16,27 +23,62 @@ func Execute() {
 	}
 	var path string
 	root.PersistentFlags().StringVar(&path, "configuration-file", "", "path to the proxy configuration file")
+	var level string
+	root.PersistentFlags().StringVar(&level, "log-level", "",
+		"log level (debug, info, warn or error), overriding that in the configuration")
 	var c configuration.Configuration
-	cobra.OnInitialize(func() { loadConfiguration(&path, &c) })
-	root.AddCommand(serveCommand(&c))
+	cobra.OnInitialize(func() { c, l = loadConfiguration(path, level, l) })
+	root.AddCommand(serveCommand(&c, &l))
 	_ = root.Execute()
 }
 
-// initialiseLogging initialises the logging package
-func initialiseLogging() func() {
-	flush, err := log.Initialise()
+// loadConfiguration loads the configuration from the path, logging with the logger,
+// then builds the logger it configures, at the level if it is set
+func loadConfiguration(path string, level string, l log.Logger) (configuration.Configuration, log.Logger) {
+	override, err := parseLevel(level)
 	if err != nil {
+		l.Errorf("Invalid log level: %s", err)
 		panic(err)
 	}
-	return flush
+	if override != nil {
+		l = buildLogger(log.Options{Level: *override, Encoding: configuration.LogJSON}, l)
+	}
+	c, err := configuration.Load(path, mapper.NewRegistry(), l)
+	if err != nil {
+		l.Errorf("Failed to load configuration: %s", err)
+		panic(err)
+	}
+	o := log.Options{
+		Level:      c.Logging.ZapLevel,
+		Encoding:   c.Logging.Encoding,
+		Outputs:    c.Logging.Outputs,
+		Initial:    c.Logging.Sampling.Initial,
+		Thereafter: c.Logging.Sampling.Thereafter,
+	}
+	if override != nil {
+		o.Level = *override
+	}
+	return c, buildLogger(o, l)
+}
+
+// parseLevel parses the log level, returning nil if it is not set
+func parseLevel(level string) (*zapcore.Level, error) {
+	if level == "" {
+		return nil, nil
+	}
+	l, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
 }
 
-// loadConfiguration loads the configuration from the passed path into the passed struct
-func loadConfiguration(path *string, c *configuration.Configuration) {
-	cl, err := configuration.Load(*path)
+// buildLogger builds a root logger from the options, logging any failure with the current logger
+func buildLogger(o log.Options, current log.Logger) *log.Root {
+	r, err := log.Build(o)
 	if err != nil {
-		log.L().Errorf("Failed to load configuration: %s", err)
+		current.Errorf("Failed to build logger: %s", err)
 		panic(err)
 	}
-	*c = cl
+	return r
 }
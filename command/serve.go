@@ -2,117 +2,218 @@ package command
 
 import (
 	"context"
+	"crypto/tls"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 
+	"github.com/snasphysicist/ferp/v2/pkg/access"
 	"github.com/snasphysicist/ferp/v2/pkg/configuration"
 	"github.com/snasphysicist/ferp/v2/pkg/log"
 	"github.com/snasphysicist/ferp/v2/pkg/server"
+	"github.com/snasphysicist/ferp/v2/pkg/systemd"
 	"github.com/spf13/cobra"
 )
 
 // serveCommand sets up the command for starting the reverse proxy server
-func serveCommand(c *configuration.Configuration) *cobra.Command {
+func serveCommand(c *configuration.Configuration, l *log.Logger) *cobra.Command {
 	return &cobra.Command{
 		Use:   "serve",
 		Short: "start the reverse proxy & run until shutdown by a signal",
 		Long:  "start the reverse proxy & run until shutdown by a signal",
-		Run:   func(*cobra.Command, []string) { Serve(*c, make(chan struct{})) },
+		Run:   func(*cobra.Command, []string) { Serve(*c, *l, make(chan struct{})) },
 	}
 }
 
-// Serve starts the HTTP and HTTPS proxy servers, and runs them until signalled
-func Serve(c configuration.Configuration, stop chan struct{}) {
-	shutdown := make(chan struct{})
-	startInsecure(c, shutdown)
-	startSecure(c, shutdown)
-	go shutDownOnSignalOrStop(shutdown, stop)
-	<-shutdown
+// Serve starts all the proxy servers, and runs them until signalled, then shuts them down.
+// If signalled to upgrade, the servers are shut down once a new process has taken over their listeners.
+// Everything is logged with the logger, which can be switched to debug if it is a log.Root.
+func Serve(c configuration.Configuration, l log.Logger, stop chan struct{}) {
+	ls, err := server.InheritListeners(l)
+	if err != nil {
+		l.Errorf("Failed to inherit listeners: %s", err)
+		panic(err)
+	}
+	rd := &server.Readiness{Path: c.Shutdown.ReadinessPath}
+	al, err := access.Open(c.AccessLog, l)
+	if err != nil {
+		l.Errorf("Failed to open access log: %s", err)
+		panic(err)
+	}
+	defer closeLoggingErrors(al, l)
+	prepared := make([]preparedServer, 0)
+	for _, s := range c.Servers {
+		if ps, ok := prepare(s, c.RequestID, rd, al, ls, l); ok {
+			prepared = append(prepared, ps)
+		}
+	}
+	ls.CloseUnused()
+	if err := dropPrivileges(c, l); err != nil {
+		l.Errorf("Failed to drop privileges: %s", err)
+		panic(err)
+	}
+	for _, ps := range prepared {
+		ps.start()
+	}
+	notifySystemd(l, systemd.Ready, systemd.MainPID())
+	notifyUpgraded(l)
+	stopWatchdog := make(chan struct{})
+	defer close(stopWatchdog)
+	go systemd.RunWatchdog(stopWatchdog, l)
+	upgraded := waitForShutdownOrUpgrade(stop, ls, l)
+	// after an upgrade the new process is still serving, so the proxy stays ready
+	if !upgraded {
+		notifySystemd(l, systemd.Stopping)
+		rd.Stop()
+	}
+	if !upgraded && c.Shutdown.Delay > 0 {
+		l.Infof("Failing readiness checks for %s before draining", c.Shutdown.Delay)
+		time.Sleep(c.Shutdown.Delay)
+	}
+	var wg sync.WaitGroup
+	for _, ps := range prepared {
+		wg.Add(1)
+		go func(ps preparedServer) {
+			defer wg.Done()
+			shutDown(ps.name, ps.server, c.Shutdown.Drain, l)
+		}(ps)
+	}
+	wg.Wait()
 }
 
-// startInsecure starts the HTTP server according to its configuration, if needed
-func startInsecure(c configuration.Configuration, shutdown <-chan struct{}) {
-	if len(c.HTTP.Incoming) == 0 && len(c.HTTP.Redirects) == 0 {
-		log.L().Infof("No HTTP routes or redirects configured, not starting HTTP")
-		return
-	}
+// preparedServer is a proxy server with its listeners open and certificates loaded, ready to start
+type preparedServer struct {
+	name      string
+	server    server.Server
+	listeners []net.Listener
+	secure    bool // certificates are loaded into the TLS configuration of the server
+	l         log.Logger
+}
 
-	insecure := server.HTTP(c.HTTP)
-	go func() {
-		log.L().Infof("Starting http server on %s", insecure.Addr)
-		err := insecure.ListenAndServe()
-		if err != nil && err != http.ErrServerClosed {
-			log.L().Errorf("http server stopped with %s", err)
+// prepare sets up the server according to its configuration, if needed,
+// opening its listeners and loading its certificates, returning false if it is not needed
+func prepare(
+	c configuration.Server, rid configuration.RequestID, rd *server.Readiness, al *access.Log,
+	ls *server.Listeners, l log.Logger,
+) (preparedServer, bool) {
+	if !configuration.Serves(c) {
+		l.Infof("No routes or redirects configured for server %s, not starting it", c.Name)
+		return preparedServer{}, false
+	}
+	s := server.New(c, rid, rd, al, l)
+	if c.TLS.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.TLS.CertFile, c.TLS.KeyFile)
+		if err != nil {
+			l.Errorf("Failed to load certificate for server %s: %s", c.Name, err)
 			panic(err)
 		}
-	}()
-	go shutDownGracefully(shutdown, insecure)
+		s.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+	nls, err := ls.Listen(c.Name, c.Port, c.Listen)
+	if err != nil {
+		l.Errorf("Failed to start server %s: %s", c.Name, err)
+		panic(err)
+	}
+	return preparedServer{name: c.Name, server: s, listeners: nls, secure: c.TLS.CertFile != "", l: l}, true
 }
 
-// startSecure starts the HTTPS server according to its configuration, if needed
-func startSecure(c configuration.Configuration, shutdown <-chan struct{}) {
-	if len(c.HTTPS.Incoming) == 0 && len(c.HTTPS.Redirects) == 0 {
-		log.L().Infof("No HTTPS routes or redirects configured, not starting HTTPS")
-		return
+// start serves on each of the listeners in the background
+func (ps preparedServer) start() {
+	for _, nl := range ps.listeners {
+		go func(nl net.Listener) {
+			ps.l.Infof("Starting server %s on %s (TLS: %t)", ps.name, nl.Addr(), ps.secure)
+			err := serve(ps.server.Server, nl, ps.secure)
+			if err != nil && err != http.ErrServerClosed {
+				ps.l.Errorf("server %s stopped with %s", ps.name, err)
+				panic(err)
+			}
+		}(nl)
 	}
+}
 
-	ensureExists(c.HTTPS.CertFile)
-	ensureExists(c.HTTPS.KeyFile)
-	secure := server.HTTPS(c.HTTPS)
-	go func() {
-		log.L().Infof("Starting https server on %s", secure.Addr)
-		err := secure.ListenAndServeTLS(c.HTTPS.CertFile, c.HTTPS.KeyFile)
-		if err != nil && err != http.ErrServerClosed {
-			log.L().Errorf("https server stopped with %s", err)
-			panic(err)
-		}
-	}()
-	go shutDownGracefully(shutdown, secure)
+// serve serves on the listener, using TLS with the loaded certificates if secure
+func serve(s *http.Server, l net.Listener, secure bool) error {
+	if !secure {
+		return s.Serve(l)
+	}
+	return s.ServeTLS(l, "", "")
 }
 
-// shutDownOnSignal closes the shutdown channel if any OS signal or signal on stop is received
-func shutDownOnSignalOrStop(shutdown chan<- struct{}, stop <-chan struct{}) {
+// waitForShutdownOrUpgrade returns once a shutdown signal from the OS or a signal on stop is received,
+// or once a new process has taken over the listeners after an upgrade signal (SIGUSR2), returning
+// true in the latter case. If the upgrade fails, this process continues to wait.
+// A debug signal (SIGUSR1) switches logging to or from the debug level, then continues waiting.
+func waitForShutdownOrUpgrade(stop <-chan struct{}, ls *server.Listeners, l log.Logger) bool {
 	s := make(chan os.Signal, 1)
-	signal.Notify(s, os.Interrupt, syscall.SIGHUP, syscall.SIGINT)
-	select {
-	case sgn := <-s:
-		log.L().Infof("Received system shutdown signal %v", sgn)
-	case <-stop:
-		log.L().Infof("Received internal shutdown signal")
-	}
-	close(shutdown)
+	signal.Notify(s, os.Interrupt, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGUSR1, syscall.SIGUSR2)
+	defer signal.Stop(s)
+	for {
+		select {
+		case sgn := <-s:
+			if sgn == syscall.SIGUSR1 {
+				toggleDebug(l, sgn)
+				continue
+			}
+			if sgn != syscall.SIGUSR2 {
+				l.Infof("Received system shutdown signal %v", sgn)
+				return false
+			}
+			l.Infof("Received upgrade signal %v, starting new process", sgn)
+			if err := upgrade(ls, l); err != nil {
+				l.Errorf("Failed to upgrade, continuing to serve: %s", err)
+				continue
+			}
+			l.Infof("New process is serving, shutting down")
+			return true
+		case <-stop:
+			l.Infof("Received internal shutdown signal")
+			return false
+		}
+	}
 }
 
-// shutDownGracefully attempts to shut down anything
-// with a Shutdown method gracefully and logs any errors on shutdown
-func shutDownGracefully(shutdown <-chan struct{}, s shutdowner) {
-	<-shutdown
-	if err := s.Shutdown(context.Background()); err != nil {
-		log.L().Errorf("Failed to shut down %#v: %s", s, err)
+// shutDown shuts down the server gracefully, waiting at most the drain duration
+// for in-flight requests to complete before closing all remaining connections
+func shutDown(name string, s server.Server, drain time.Duration, l log.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), drain)
+	defer cancel()
+	err := s.Shutdown(ctx)
+	if err == nil {
+		l.Infof("Shut down server %s gracefully", name)
+		return
+	}
+	l.Errorf("Failed to shut down server %s gracefully within %s, cutting off %d in-flight requests: %s",
+		name, drain, s.InFlight(), err)
+	if err := s.Close(); err != nil {
+		l.Errorf("Failed to close server %s: %s", name, err)
 	}
 }
 
-// shutdowner represents something which can be requested to shutdown gracefully
-type shutdowner interface {
-	Shutdown(context.Context) error
+// toggleDebug switches the logger to or from the debug level on the signal, if it is a log.Root
+func toggleDebug(l log.Logger, sgn os.Signal) {
+	r, ok := l.(*log.Root)
+	if !ok {
+		l.Errorf("Received debug signal %v, but the log level cannot be changed", sgn)
+		return
+	}
+	l.Infof("Received debug signal %v, log level is now %s", sgn, r.ToggleDebug())
 }
 
-// ensureExists panics if a file at the given path does not exist
-func ensureExists(path string) {
-	f, err := os.Open(path)
-	defer closeLoggingErrors(f)
-	if err != nil {
-		log.L().Errorf("Failed to open %s", path)
-		panic(err)
+// notifySystemd notifies systemd of the states, logging any error encountered
+func notifySystemd(l log.Logger, states ...string) {
+	if err := systemd.Notify(states...); err != nil {
+		l.Errorf("Failed to notify systemd: %s", err)
 	}
 }
 
 // closeLoggingErrors closes a closeable and logs any errors encountered on close
-func closeLoggingErrors(c io.Closer) {
+func closeLoggingErrors(c io.Closer, l log.Logger) {
 	if err := c.Close(); err != nil {
-		log.L().Errorf("Failed to close %#v: %s", c, err)
+		l.Errorf("Failed to close %#v: %s", c, err)
 	}
 }
@@ -0,0 +1,133 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+	"github.com/snasphysicist/ferp/v2/pkg/server"
+	"github.com/snasphysicist/ferp/v2/pkg/systemd"
+)
+
+// envUpgradeReady is the file descriptor on which a process started
+// by an upgrade should report that it is serving
+const envUpgradeReady = "FERP_UPGRADE_READY_FD"
+
+// upgradeTimeout is how long to wait for the new process to start serving before giving up
+const upgradeTimeout = time.Minute
+
+// upgrade starts a new process from the (possibly replaced) executable with the same arguments,
+// handing it all the listeners, and returns once it reports that it is serving,
+// or an error if it fails to start or exits without doing so, in which case it is killed
+// and this process takes back the listeners
+func upgrade(ls *server.Listeners, l log.Logger) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	files, desc, err := ls.HandOff()
+	if err != nil {
+		return err
+	}
+	defer server.CloseFiles(files, l)
+	cmd, err := startUpgrade(exe, files, desc, l)
+	if err != nil {
+		ls.TakeBack()
+		return err
+	}
+	// reaped once it exits, which may be after this process has shut down
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+// startUpgrade starts the new process from the executable, passing it the files
+// of the listeners described by desc, and waits for it to report that it is serving.
+// If it does not, it is killed and reaped, and an error returned.
+func startUpgrade(exe string, files []*os.File, desc string, l log.Logger) (*exec.Cmd, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	defer closeLoggingErrors(r, l)
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = append(files, w)
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("%s=%s", server.EnvInheritedListeners, desc),
+		fmt.Sprintf("%s=%d", envUpgradeReady, systemd.ListenFDsStart+len(files)))
+	if _, ok := os.LookupEnv("WATCHDOG_PID"); ok {
+		// so that the new process knows the watchdog was for its parent
+		cmd.Env = append(cmd.Env, fmt.Sprintf("WATCHDOG_PID=%d", os.Getpid()))
+	}
+	err = cmd.Start()
+	closeLoggingErrors(w, l)
+	if err != nil {
+		return nil, err
+	}
+	l.Infof("Started new process %d from %s, waiting for it to serve", cmd.Process.Pid, exe)
+	if err := waitForUpgrade(cmd, r); err != nil {
+		killUpgrade(cmd, l)
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// waitForUpgrade waits for the started process to report on the pipe that it is serving
+func waitForUpgrade(cmd *exec.Cmd, r *os.File) error {
+	ready := make(chan error, 1)
+	go func() {
+		// the pipe is closed without anything written if the new process exits
+		_, err := r.Read(make([]byte, 1))
+		ready <- err
+	}()
+	select {
+	case err := <-ready:
+		if err == io.EOF {
+			return fmt.Errorf("new process %d exited without serving", cmd.Process.Pid)
+		}
+		return err
+	case <-time.After(upgradeTimeout):
+		return fmt.Errorf("new process %d did not start serving within %s", cmd.Process.Pid, upgradeTimeout)
+	}
+}
+
+// killUpgrade kills the started process, if it is still running, and reaps it,
+// so that it neither keeps serving on the listeners nor is left as a zombie
+func killUpgrade(cmd *exec.Cmd, l log.Logger) {
+	if err := cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		l.Errorf("Failed to kill new process %d: %s", cmd.Process.Pid, err)
+	}
+	// an error is expected here, since the process did not exit successfully
+	_ = cmd.Wait()
+}
+
+// notifyUpgraded reports to the process which started this one,
+// if it was started by an upgrade, that this one is now serving
+func notifyUpgraded(l log.Logger) {
+	fd, ok := os.LookupEnv(envUpgradeReady)
+	if !ok {
+		return
+	}
+	if err := os.Unsetenv(envUpgradeReady); err != nil {
+		l.Errorf("Failed to unset %s: %s", envUpgradeReady, err)
+	}
+	n, err := strconv.Atoi(fd)
+	if err != nil {
+		l.Errorf("Invalid %s '%s', cannot report upgrade: %s", envUpgradeReady, fd, err)
+		return
+	}
+	f := os.NewFile(uintptr(n), "upgrade ready")
+	defer closeLoggingErrors(f, l)
+	if _, err := f.Write([]byte{1}); err != nil {
+		l.Errorf("Failed to report upgrade to previous process: %s", err)
+		return
+	}
+	l.Infof("Reported to previous process that the upgrade is serving")
+}
@@ -0,0 +1,70 @@
+package access
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/snasphysicist/ferp/v2/pkg/request"
+)
+
+// Entry is what the access log records about one request
+type Entry struct {
+	Time             time.Time // when the request was received
+	ClientIP         string
+	Method           string
+	URI              string
+	Protocol         string
+	Host             string
+	Route            string // the pattern of the route which matched, if any
+	Target           string // the downstream the request was forwarded to, if any
+	Upstream         string // the address of the downstream the request was forwarded to, if any
+	Status           int
+	Bytes            int64
+	RequestBytes     int64
+	Duration         time.Duration
+	FirstByte        time.Duration // until the response headers were written
+	UpstreamDuration time.Duration // until the response headers were received from the downstream
+	RequestID        string
+	Referer          string
+	UserAgent        string
+}
+
+// NewEntry creates the entry for the request, which matched the route, once it has been handled
+func NewEntry(r *http.Request, route string, m *request.Metrics) Entry {
+	return Entry{
+		Time:             m.Start,
+		ClientIP:         clientIP(r.RemoteAddr),
+		Method:           r.Method,
+		URI:              r.RequestURI,
+		Protocol:         r.Proto,
+		Host:             r.Host,
+		Route:            route,
+		Target:           m.Target,
+		Upstream:         m.Upstream,
+		Status:           m.Status(),
+		Bytes:            m.BytesWritten(),
+		RequestBytes:     m.BytesRead(),
+		Duration:         time.Since(m.Start),
+		FirstByte:        m.FirstByte(),
+		UpstreamDuration: m.UpstreamDuration,
+		RequestID:        requestID(r),
+		Referer:          r.Referer(),
+		UserAgent:        r.UserAgent(),
+	}
+}
+
+// requestID is the ID by which the request was identified, if it was
+func requestID(r *http.Request) string {
+	id, _ := request.IDFrom(r.Context())
+	return id.Value
+}
+
+// clientIP extracts the IP from the remote address, if it has one (i.e. is not a unix socket)
+func clientIP(remote string) string {
+	ip, _, err := net.SplitHostPort(remote)
+	if err != nil {
+		return ""
+	}
+	return ip
+}
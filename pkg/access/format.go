@@ -0,0 +1,116 @@
+package access
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/snasphysicist/ferp/v2/pkg/configuration"
+)
+
+// formatter formats an entry as one line of the access log, without the trailing newline
+type formatter func(Entry) ([]byte, error)
+
+// formatterFor returns the formatter for the (validated) format
+func formatterFor(format string) formatter {
+	switch format {
+	case configuration.AccessLogCombined:
+		return combined
+	case configuration.AccessLogJSON:
+		return jsonLine
+	default:
+		return common
+	}
+}
+
+// common formats the entry in the Apache common log format
+func common(e Entry) ([]byte, error) {
+	return []byte(fmt.Sprintf(`%s - - [%s] "%s %s %s" %d %s`,
+		orDash(e.ClientIP), e.Time.Format("02/Jan/2006:15:04:05 -0700"),
+		escape(e.Method), escape(e.URI), escape(e.Protocol), e.Status, bytesOrDash(e.Bytes))), nil
+}
+
+// combined formats the entry in the Apache combined log format
+func combined(e Entry) ([]byte, error) {
+	c, err := common(e)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf(`%s "%s" "%s"`, c, escape(orDash(e.Referer)), escape(orDash(e.UserAgent)))), nil
+}
+
+// jsonEntry is an entry as written to the access log in the JSON format
+type jsonEntry struct {
+	Time               string  `json:"time"`
+	ClientIP           string  `json:"client_ip,omitempty"`
+	Method             string  `json:"method"`
+	URI                string  `json:"uri"`
+	Protocol           string  `json:"protocol"`
+	Host               string  `json:"host"`
+	Route              string  `json:"route,omitempty"`
+	Target             string  `json:"target,omitempty"`
+	Upstream           string  `json:"upstream,omitempty"`
+	Status             int     `json:"status"`
+	Bytes              int64   `json:"bytes"`
+	RequestBytes       int64   `json:"request_bytes"`
+	DurationMS         float64 `json:"duration_ms"`
+	FirstByteMS        float64 `json:"first_byte_ms"`
+	UpstreamDurationMS float64 `json:"upstream_duration_ms,omitempty"`
+	RequestID          string  `json:"request_id,omitempty"`
+	Referer            string  `json:"referer,omitempty"`
+	UserAgent          string  `json:"user_agent,omitempty"`
+}
+
+// jsonLine formats the entry as a JSON object
+func jsonLine(e Entry) ([]byte, error) {
+	return json.Marshal(jsonEntry{
+		Time:               e.Time.Format(time.RFC3339Nano),
+		ClientIP:           e.ClientIP,
+		Method:             e.Method,
+		URI:                e.URI,
+		Protocol:           e.Protocol,
+		Host:               e.Host,
+		Route:              e.Route,
+		Target:             e.Target,
+		Upstream:           e.Upstream,
+		Status:             e.Status,
+		Bytes:              e.Bytes,
+		RequestBytes:       e.RequestBytes,
+		DurationMS:         milliseconds(e.Duration),
+		FirstByteMS:        milliseconds(e.FirstByte),
+		UpstreamDurationMS: milliseconds(e.UpstreamDuration),
+		RequestID:          e.RequestID,
+		Referer:            e.Referer,
+		UserAgent:          e.UserAgent,
+	})
+}
+
+// milliseconds converts the duration to (fractional) milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// orDash returns the string, or - if it is empty, as in the Apache formats
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// bytesOrDash returns the number of bytes, or - if there are none, as in the Apache formats
+func bytesOrDash(n int64) string {
+	if n == 0 {
+		return "-"
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// escape escapes quotes, backslashes & non-printable characters so that
+// values sent by the client cannot break the structure of the line
+func escape(s string) string {
+	quoted := strconv.QuoteToASCII(s)
+	return strings.TrimSuffix(strings.TrimPrefix(quoted, `"`), `"`)
+}
@@ -0,0 +1,104 @@
+package access
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// testEntry is an entry with every field set
+func testEntry() Entry {
+	return Entry{
+		Time:             time.Date(2000, time.October, 10, 13, 55, 36, 0, time.FixedZone("", -7*60*60)),
+		ClientIP:         "127.0.0.1",
+		Method:           "GET",
+		URI:              "/apache_pb.gif?q=\"x\"",
+		Protocol:         "HTTP/1.1",
+		Host:             "example.com",
+		Route:            "/apache_pb.gif",
+		Target:           "images",
+		Upstream:         "10.0.0.1:8080",
+		Status:           200,
+		Bytes:            2326,
+		RequestBytes:     12,
+		Duration:         1500 * time.Microsecond,
+		FirstByte:        1200 * time.Microsecond,
+		UpstreamDuration: time.Millisecond,
+		RequestID:        "abc",
+		Referer:          "http://www.example.com/start.html",
+		UserAgent:        "Mozilla/4.08",
+	}
+}
+
+func TestCommonFormat(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		update func(e *Entry)
+		expect string
+	}{
+		{name: "all set", update: func(*Entry) {},
+			expect: `127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif?q=\"x\" HTTP/1.1" 200 2326`},
+		{name: "no body or client IP", update: func(e *Entry) { e.Bytes = 0; e.ClientIP = "" },
+			expect: `- - - [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif?q=\"x\" HTTP/1.1" 200 -`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := testEntry()
+			tc.update(&e)
+			b, err := common(e)
+			if err != nil {
+				t.Fatalf("Failed to format %#v: %s", e, err)
+			}
+			if string(b) != tc.expect {
+				t.Errorf("Formatted as '%s', expected '%s'", b, tc.expect)
+			}
+		})
+	}
+}
+
+func TestCombinedFormat(t *testing.T) {
+	e := testEntry()
+	e.UserAgent = "evil\"\n agent"
+	b, err := combined(e)
+	if err != nil {
+		t.Fatalf("Failed to format %#v: %s", e, err)
+	}
+	expect := `127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif?q=\"x\" HTTP/1.1" 200 2326` +
+		` "http://www.example.com/start.html" "evil\"\n agent"`
+	if string(b) != expect {
+		t.Errorf("Formatted as '%s', expected '%s'", b, expect)
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	b, err := jsonLine(testEntry())
+	if err != nil {
+		t.Fatalf("Failed to format: %s", err)
+	}
+	var je jsonEntry
+	if err := json.Unmarshal(b, &je); err != nil {
+		t.Fatalf("Failed to parse %s: %s", b, err)
+	}
+	expect := jsonEntry{
+		Time:               "2000-10-10T13:55:36-07:00",
+		ClientIP:           "127.0.0.1",
+		Method:             "GET",
+		URI:                "/apache_pb.gif?q=\"x\"",
+		Protocol:           "HTTP/1.1",
+		Host:               "example.com",
+		Route:              "/apache_pb.gif",
+		Target:             "images",
+		Upstream:           "10.0.0.1:8080",
+		Status:             200,
+		Bytes:              2326,
+		RequestBytes:       12,
+		DurationMS:         1.5,
+		FirstByteMS:        1.2,
+		UpstreamDurationMS: 1,
+		RequestID:          "abc",
+		Referer:            "http://www.example.com/start.html",
+		UserAgent:          "Mozilla/4.08",
+	}
+	if je != expect {
+		t.Errorf("Formatted as %+v, expected %+v", je, expect)
+	}
+}
@@ -0,0 +1,68 @@
+package access
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"sync"
+
+	"github.com/snasphysicist/ferp/v2/pkg/configuration"
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+)
+
+// Log writes entries to the access log. A nil Log is disabled, writing nothing.
+type Log struct {
+	format formatter
+	sample float64
+	mu     sync.Mutex
+	out    io.Writer
+	close  func() error
+	l      log.Logger // for errors writing the log
+}
+
+// Open opens the output of the (validated) access log configuration,
+// returning nil if the access log is disabled. Errors writing it are logged with the logger.
+func Open(c configuration.AccessLog, lg log.Logger) (*Log, error) {
+	if c.Format == "" {
+		return nil, nil
+	}
+	l := &Log{format: formatterFor(c.Format), sample: *c.Sample, l: lg}
+	if c.Output == configuration.AccessLogStdout {
+		l.out = os.Stdout
+		l.close = func() error { return nil }
+		return l, nil
+	}
+	f, err := os.OpenFile(c.Output, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open access log %s: %w", c.Output, err)
+	}
+	l.out = f
+	l.close = f.Close
+	return l, nil
+}
+
+// Write writes the entry to the log, unless it is a successful request which is not sampled
+func (l *Log) Write(e Entry) {
+	if l == nil || (e.Status < 400 && rand.Float64() >= l.sample) {
+		return
+	}
+	b, err := l.format(e)
+	if err != nil {
+		l.l.Errorf("Failed to format access log entry %#v: %s", e, err)
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.out.Write(append(b, '\n')); err != nil {
+		l.l.Errorf("Failed to write access log entry: %s", err)
+	}
+}
+
+// Close closes the output of the log, if it is a file
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.close()
+}
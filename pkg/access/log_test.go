@@ -0,0 +1,35 @@
+package access
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/snasphysicist/ferp/v2/pkg/configuration"
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+)
+
+func TestUnsuccessfulRequestsAreAlwaysLogged(t *testing.T) {
+	b := &bytes.Buffer{}
+	l := &Log{format: formatterFor(configuration.AccessLogCommon), sample: 0.000001, out: b}
+	for _, status := range []int{200, 302, 404, 502} {
+		e := testEntry()
+		e.Status = status
+		l.Write(e)
+	}
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], " 404 ") || !strings.Contains(lines[1], " 502 ") {
+		t.Errorf("Logged %#v, expected only the 404 & 502 requests", lines)
+	}
+}
+
+func TestDisabledLogWritesNothing(t *testing.T) {
+	l, err := Open(configuration.AccessLog{}, log.Nop())
+	if err != nil {
+		t.Fatalf("Failed to open disabled log: %s", err)
+	}
+	l.Write(testEntry())
+	if err := l.Close(); err != nil {
+		t.Errorf("Failed to close disabled log: %s", err)
+	}
+}
@@ -0,0 +1,45 @@
+package configuration
+
+import (
+	"fmt"
+
+	"github.com/snasphysicist/ferp/v2/pkg/functional"
+)
+
+// The formats in which the access log can be written
+const (
+	// AccessLogCommon is the Apache common log format
+	AccessLogCommon = "common"
+	// AccessLogCombined is the Apache combined log format, common plus referer & user agent
+	AccessLogCombined = "combined"
+	// AccessLogJSON writes each entry as a JSON object on its own line
+	AccessLogJSON = "json"
+)
+
+// AccessLogStdout is the access log output which writes to standard output
+const AccessLogStdout = "stdout"
+
+// validateAccessLog sets the default output & sample rate of the access log if it is enabled,
+// then checks that the format is known and the sample rate is a fraction
+func validateAccessLog(c Configuration) (Configuration, error) {
+	al := c.AccessLog
+	if al.Format == "" {
+		return c, nil
+	}
+	errs := make([]error, 0)
+	if !functional.Contains([]string{AccessLogCommon, AccessLogCombined, AccessLogJSON}, al.Format) {
+		errs = append(errs, fmt.Errorf("unknown format '%s'", al.Format))
+	}
+	if al.Sample == nil {
+		all := 1.0
+		al.Sample = &all
+	}
+	if *al.Sample < 0 || *al.Sample > 1 {
+		errs = append(errs, fmt.Errorf("sample %f must be between 0 and 1", *al.Sample))
+	}
+	if al.Output == "" {
+		al.Output = AccessLogStdout
+	}
+	c.AccessLog = al
+	return c, joinNonNilErrors(errs, ", ", "invalid access log: %s")
+}
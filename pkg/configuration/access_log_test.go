@@ -0,0 +1,49 @@
+package configuration
+
+import (
+	"testing"
+)
+
+func TestAccessLogDefaultsWhenEnabled(t *testing.T) {
+	c, err := validateAccessLog(Configuration{AccessLog: AccessLog{Format: AccessLogJSON}})
+	if err != nil {
+		t.Fatalf("Failed to validate access log: %s", err)
+	}
+	if c.AccessLog.Output != AccessLogStdout || c.AccessLog.Sample == nil || *c.AccessLog.Sample != 1 {
+		t.Errorf("Access log is %+v, expected output %s and sample 1", c.AccessLog, AccessLogStdout)
+	}
+}
+
+func TestAccessLogSampleOfZeroIsKept(t *testing.T) {
+	none := 0.0
+	c, err := validateAccessLog(Configuration{AccessLog: AccessLog{Format: AccessLogJSON, Sample: &none}})
+	if err != nil {
+		t.Fatalf("Failed to validate access log: %s", err)
+	}
+	if *c.AccessLog.Sample != 0 {
+		t.Errorf("Sample is %f, expected the configured 0 so only errors are logged", *c.AccessLog.Sample)
+	}
+}
+
+func TestAccessLogIsNotRequired(t *testing.T) {
+	c, err := validateAccessLog(Configuration{})
+	if err != nil {
+		t.Fatalf("Failed to validate empty access log: %s", err)
+	}
+	if c.AccessLog != (AccessLog{}) {
+		t.Errorf("Access log is %+v, expected it to stay empty", c.AccessLog)
+	}
+}
+
+func TestInvalidAccessLogsAreInvalid(t *testing.T) {
+	negative, more := -0.5, 1.5
+	for _, al := range []AccessLog{
+		{Format: "apache"},
+		{Format: AccessLogCommon, Sample: &negative},
+		{Format: AccessLogCombined, Sample: &more},
+	} {
+		if _, err := validateAccessLog(Configuration{AccessLog: al}); err == nil {
+			t.Errorf("Validated access log %+v, but should not be possible", al)
+		}
+	}
+}
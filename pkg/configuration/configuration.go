@@ -1,46 +1,181 @@
 package configuration
 
-import "github.com/snasphysicist/ferp/v2/pkg/configuration/router"
+import (
+	"os"
+	"time"
+
+	"github.com/snasphysicist/ferp/v2/pkg/configuration/router"
+	"github.com/snasphysicist/ferp/v2/pkg/mapper"
+	"go.uber.org/zap/zapcore"
+)
 
 // Configuration holds configuration for the entire application
 type Configuration struct {
 	Downstreams []Downstream `config:"downstream"`
-	HTTP        HTTP         `config:"http"`
-	HTTPS       HTTPS        `config:"https"`
+	Servers     []Server     `config:"servers"`
+	HTTP        HTTP         `config:"http"`  // shorthand for a server named http, moved into Servers on load
+	HTTPS       HTTPS        `config:"https"` // shorthand for a server named https, moved into Servers on load
+	Shutdown    Shutdown     `config:"shutdown"`
+	RunAs       RunAs        `config:"run-as"`
+	AccessLog   AccessLog    `config:"access-log"`
+	Logging     Logging      `config:"logging"`
+	RequestID   RequestID    `config:"request-id"`
+}
+
+// RequestID configures the header in which requests are identified to & by downstreams
+type RequestID struct {
+	Header string `config:"header"` // default X-Request-ID
+}
+
+// Logging configures the application log
+type Logging struct {
+	Level    string        `config:"level"`    // debug, info, warn or error, default info
+	Encoding string        `config:"encoding"` // json (the default) or console
+	Outputs  []string      `config:"outputs"`  // stderr (the default), stdout or paths of files to append to
+	Sampling LogSampling   `config:"sampling"`
+	ZapLevel zapcore.Level `config:"-"` // populated after configuration load from Level
+}
+
+// LogSampling limits how many entries with the same message are logged each second
+type LogSampling struct {
+	Initial    int `config:"initial"`    // logged before sampling starts, default 100
+	Thereafter int `config:"thereafter"` // after which every nth entry is logged, default 100
+}
+
+// AccessLog configures the log of every request made to any of the servers
+type AccessLog struct {
+	Format string   `config:"format"` // common, combined or json, no access log is written if empty
+	Output string   `config:"output"` // stdout (the default) or the path of a file to append to
+	Sample *float64 `config:"sample"` // fraction of successful requests (status below 400) logged, default 1
+}
+
+// RunAs configures the user & group the proxy switches to once its listeners are open
+type RunAs struct {
+	User  string `config:"user"`  // name or numeric id, privileges are not dropped if empty
+	Group string `config:"group"` // name or numeric id, the primary group of the user if empty
+	UID   int    `config:"-"`     // populated after configuration load from User
+	GID   int    `config:"-"`     // populated after configuration load from Group or User
+}
+
+// Shutdown configures how the servers are shut down when a signal is received
+type Shutdown struct {
+	ReadinessPath string        `config:"readiness-path"` // if set, every server responds 200 here until shutdown starts
+	Delay         time.Duration `config:"delay"`          // after readiness checks start failing, before draining
+	Drain         time.Duration `config:"drain"`          // to wait for in-flight requests before closing connections
 }
 
 // Downstream represents a server that the proxy is providing access to
 type Downstream struct {
-	Target     string            `config:"target"`
-	Protocol   string            `config:"protocol"`
-	Host       string            `config:"host"`
-	Port       uint16            `config:"port"`
-	Base       string            `config:"base"`
-	MapperData map[string]string `config:"path-mapper"`
-	Mapper     pathMapper        `config:"-"`
+	Target           string                   `config:"target"`
+	Protocol         string                   `config:"protocol"`
+	Host             string                   `config:"host"`
+	Port             uint16                   `config:"port"`
+	Base             string                   `config:"base"`
+	MapperData       []map[string]interface{} `config:"path-mapper"` // a single mapper is decoded as a chain of one
+	Mapper           mapper.Chain             `config:"-"`
+	Query            []QueryRule              `config:"query"`
+	Headers          HeaderRules              `config:"headers"`
+	HostHeader       HostHeader               `config:"host-header"`
+	RewriteResponses bool                     `config:"rewrite-responses"` // map URLs & cookies in responses back to the proxy
+}
+
+// HostHeader configures which Host header is sent to a downstream
+type HostHeader struct {
+	Policy string `config:"policy"`
+	Value  string `config:"value"` // only for the fixed policy
 }
 
-// pathMapper is an object which can rewrite paths from the incoming request,
-// to the downstream request, and can deserialise itself from a set of kv pairs
-type pathMapper interface {
-	Map(string) string
-	From(map[string]string) error
+// QueryRule configures a change to the query parameters of a request forwarded to a downstream
+type QueryRule struct {
+	Action string `config:"action"`
+	Name   string `config:"name"`
+	Value  string `config:"value"`
+	Env    string `config:"env"` // if set, Value is read from this environment variable on configuration load
+	To     string `config:"to"`
+}
+
+// HeaderRules configures changes to the headers of requests forwarded
+// to a downstream, and of the responses from it
+type HeaderRules struct {
+	Request  []HeaderRule `config:"request"`
+	Response []HeaderRule `config:"response"`
+}
+
+// HeaderRule configures a change to one header
+type HeaderRule struct {
+	Action string            `config:"action"`
+	Name   string            `config:"name"`
+	Value  string            `config:"value"` // may contain {variables}
+	To     string            `config:"to"`
+	Env    map[string]string `config:"-"` // values of the {env:NAME} variables in Value, read on configuration load
+}
+
+// Server holds configuration for one of the proxy servers
+type Server struct {
+	Name         string        `config:"name"`
+	Port         uint16        `config:"port"` // listens on all interfaces at this port if Listen is empty
+	Listen       []Listen      `config:"listen"`
+	TLS          TLS           `config:"tls"`
+	Redirects    []Redirect    `config:"redirects"`
+	RedirectMaps []RedirectMap `config:"redirect-maps"`
+	Incoming     []Incoming    `config:"incoming"`
+	Limits       Limits        `config:"limits"`
+	Timeouts     Timeouts      `config:"timeouts"`
+}
+
+// TLS configures a server to serve HTTPS, if the files are set
+type TLS struct {
+	CertFile string `config:"cert-file"`
+	KeyFile  string `config:"key-file"`
 }
 
 // HTTP holds configuration for the HTTP proxy server
 type HTTP struct {
-	Port      uint16     `config:"port"`
-	Redirects []Redirect `config:"redirects"`
-	Incoming  []Incoming `config:"incoming"`
+	Port         uint16        `config:"port"` // listens on all interfaces at this port if Listen is empty
+	Listen       []Listen      `config:"listen"`
+	Redirects    []Redirect    `config:"redirects"`
+	RedirectMaps []RedirectMap `config:"redirect-maps"`
+	Incoming     []Incoming    `config:"incoming"`
+	Limits       Limits        `config:"limits"`
+	Timeouts     Timeouts      `config:"timeouts"`
 }
 
 // HTTPS contains configuration for routes served by the proxy over HTTPS
 type HTTPS struct {
-	Port      uint16     `config:"port"`
-	CertFile  string     `config:"cert-file"`
-	KeyFile   string     `config:"key-file"`
-	Redirects []Redirect `config:"redirects"`
-	Incoming  []Incoming `config:"incoming"`
+	Port         uint16        `config:"port"` // listens on all interfaces at this port if Listen is empty
+	Listen       []Listen      `config:"listen"`
+	CertFile     string        `config:"cert-file"`
+	KeyFile      string        `config:"key-file"`
+	Redirects    []Redirect    `config:"redirects"`
+	RedirectMaps []RedirectMap `config:"redirect-maps"`
+	Incoming     []Incoming    `config:"incoming"`
+	Limits       Limits        `config:"limits"`
+	Timeouts     Timeouts      `config:"timeouts"`
+}
+
+// Listen configures an address on which a server accepts connections
+type Listen struct {
+	Address     string      `config:"address"` // host:port, [ipv6 host]:port or unix:/path/to/socket
+	Mode        string      `config:"mode"`    // optional octal permissions of a unix socket, e.g. "0660"
+	Permissions os.FileMode `config:"-"`       // populated after configuration load from Mode
+}
+
+// Limits restricts the size and duration of requests to a server, or to one incoming.
+// Any not set on an incoming are populated from the server after configuration load.
+type Limits struct {
+	MaxBodyBytes   int64         `config:"max-body-bytes"`   // 0 for no limit
+	BodyTooLarge   string        `config:"body-too-large"`   // message sent with the 413 response
+	MaxDuration    time.Duration `config:"max-duration"`     // 0 for no limit
+	MaxHeaderBytes int           `config:"max-header-bytes"` // server only, 0 for the Go default (1MB)
+	MaxHeaders     int           `config:"max-headers"`      // server only, 0 for no limit
+}
+
+// Timeouts configures how long a server waits for each part of a request and its response
+type Timeouts struct {
+	ReadHeader time.Duration `config:"read-header"` // server only
+	Read       time.Duration `config:"read"`        // on an incoming, overrides that of the server
+	Write      time.Duration `config:"write"`       // on an incoming, overrides that of the server
+	Idle       time.Duration `config:"idle"`        // server only, between requests on a kept-alive connection
 }
 
 // Redirect configures the proxy to serve a redirect itself
@@ -48,9 +183,19 @@ type Redirect struct {
 	From          string                `config:"from"`
 	To            string                `config:"to"`
 	Methods       []string              `config:"methods"`
+	Status        int                   `config:"status"`
+	Query         string                `config:"query"`
 	MethodRouters []router.MethodRouter `config:"-"` // populated after configuration load based on Methods
 }
 
+// RedirectMap configures the proxy to serve (GET & HEAD only) redirects listed in a separate file
+type RedirectMap struct {
+	File    string              `config:"file"`
+	Status  int                 `config:"status"` // used for entries which do not set their own status
+	Query   string              `config:"query"`
+	Entries map[string]Redirect `config:"-"` // populated after configuration load from File, keyed by From
+}
+
 // Incoming represents a route that one of the proxy servers offers,
 // and the target it proxies (the downstream)
 type Incoming struct {
@@ -58,5 +203,9 @@ type Incoming struct {
 	Methods       []string              `config:"methods"`
 	MethodRouters []router.MethodRouter `config:"-"` // populated after configuration load based on Methods
 	Target        string                `config:"target"`
-	Downstream    Downstream            `config:"-"` // populated after configuration load based on Target
+	Downstream    Downstream            `config:"-"`       // populated after configuration load based on Target
+	Query         []QueryRule           `config:"query"`   // applied after those of the downstream
+	Headers       HeaderRules           `config:"headers"` // applied after those of the downstream
+	Limits        Limits                `config:"limits"`
+	Timeouts      Timeouts              `config:"timeouts"`
 }
@@ -9,11 +9,12 @@ import (
 // populateDownstreams finds downstreams for all incomings in the configuration,
 // returning an error if some cannot be found
 func populateDownstreams(c Configuration) (Configuration, error) {
-	isi, isErr := findDownstreams(c.Downstreams, c.HTTP.Incoming)
-	c.HTTP.Incoming = isi
-	si, sErr := findDownstreams(c.Downstreams, c.HTTPS.Incoming)
-	c.HTTPS.Incoming = si
-	return c, joinNonNilErrors([]error{isErr, sErr}, ", ", "%s")
+	c, errs := updateServers(c, func(s Server) (Server, error) {
+		is, err := findDownstreams(c.Downstreams, s.Incoming)
+		s.Incoming = is
+		return s, err
+	})
+	return c, joinNonNilErrors(errs, ", ", "%s")
 }
 
 // findDownstreams finds downstreams for all provided incomings, returning
@@ -24,13 +25,8 @@ func findDownstreams(d []Downstream, is []Incoming) ([]Incoming, error) {
 	for _, i := range is {
 		d, err := findDownstream(d, i)
 		errs = append(errs, err)
-		iswd = append(iswd, Incoming{
-			Path:          i.Path,
-			Methods:       i.Methods,
-			MethodRouters: i.MethodRouters,
-			Target:        i.Target,
-			Downstream:    d,
-		})
+		i.Downstream = d
+		iswd = append(iswd, i)
 	}
 	err := joinNonNilErrors(errs, ", ", "invalid downstreams: %s")
 	return iswd, err
@@ -0,0 +1,142 @@
+package configuration
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/snasphysicist/ferp/v2/pkg/functional"
+	"github.com/snasphysicist/ferp/v2/pkg/header"
+	"github.com/snasphysicist/ferp/v2/pkg/pattern"
+)
+
+// The actions a header rule can take on the headers of a forwarded request or its response
+const (
+	// HeaderSet sets the header to the value, replacing any existing values
+	HeaderSet = "set"
+	// HeaderAdd adds the value to any existing values of the header
+	HeaderAdd = "add"
+	// HeaderRemove removes the header
+	HeaderRemove = "remove"
+	// HeaderRename moves all values of the header to the header named by To
+	HeaderRename = "rename"
+)
+
+// validateHeaderRules validates the header rules for all downstreams and incomings,
+// reading environment variables used in values, and returning an error
+// summarising which rules (if any) are invalid
+func validateHeaderRules(c Configuration) (Configuration, error) {
+	errs := make([]error, 0)
+	ds := make([]Downstream, 0)
+	for _, d := range c.Downstreams {
+		hrs, err := validateHeaderRulesIn(d.Headers)
+		errs = append(errs, err)
+		d.Headers = hrs
+		ds = append(ds, d)
+	}
+	c.Downstreams = ds
+	c, serrs := updateServers(c, func(s Server) (Server, error) {
+		is, err := validateHeaderRulesForIncomings(s.Incoming)
+		s.Incoming = is
+		return s, err
+	})
+	errs = append(errs, serrs...)
+	return c, joinNonNilErrors(errs, ", ", "invalid header rules: %s")
+}
+
+// validateHeaderRulesForIncomings validates the header rules of each of the incomings
+func validateHeaderRulesForIncomings(is []Incoming) ([]Incoming, error) {
+	vis := make([]Incoming, 0)
+	errs := make([]error, 0)
+	for _, i := range is {
+		hrs, err := validateHeaderRulesIn(i.Headers)
+		errs = append(errs, err)
+		i.Headers = hrs
+		vis = append(vis, i)
+	}
+	return vis, joinNonNilErrors(errs, ", ", "%s")
+}
+
+// validateHeaderRulesIn validates each of the request and response header rules
+func validateHeaderRulesIn(hrs HeaderRules) (HeaderRules, error) {
+	vhrs := HeaderRules{Request: make([]HeaderRule, 0), Response: make([]HeaderRule, 0)}
+	errs := make([]error, 0)
+	for _, hr := range hrs.Request {
+		vhr, err := validateHeaderRule(hr)
+		errs = append(errs, err)
+		vhrs.Request = append(vhrs.Request, vhr)
+	}
+	for _, hr := range hrs.Response {
+		vhr, err := validateHeaderRule(hr)
+		errs = append(errs, err)
+		vhrs.Response = append(vhrs.Response, vhr)
+	}
+	return vhrs, joinNonNilErrors(errs, ", ", "%s")
+}
+
+// validateHeaderRule checks that the rule has a known action and the fields that action needs,
+// and that its value only uses known variables, reading any environment variables
+// (which are filled in with the request variables, so are not themselves expanded)
+func validateHeaderRule(hr HeaderRule) (HeaderRule, error) {
+	hr.Env = make(map[string]string)
+	if !functional.Contains(headerActions(), hr.Action) {
+		return hr, fmt.Errorf("header rule %+v has action '%s', must be one of %v", hr, hr.Action, headerActions())
+	}
+	if hr.Name == "" {
+		return hr, fmt.Errorf("header rule %+v has no name, required for action %s", hr, hr.Action)
+	}
+	if hr.Action == HeaderRename && hr.To == "" {
+		return hr, fmt.Errorf("header rule %+v has no to, required for action %s", hr, hr.Action)
+	}
+	for _, p := range pattern.Placeholders(hr.Value) {
+		if strings.HasPrefix(p, envPrefix) {
+			v, ok := os.LookupEnv(strings.TrimPrefix(p, envPrefix))
+			if !ok {
+				return hr, fmt.Errorf("header rule for %s reads environment variable %s, which is not set",
+					hr.Name, strings.TrimPrefix(p, envPrefix))
+			}
+			hr.Env[p] = v
+			continue
+		}
+		if !functional.Contains(header.Variables(), p) {
+			return hr, fmt.Errorf("header rule for %s uses unknown variable '%s', must be one of %v or %sNAME",
+				hr.Name, p, header.Variables(), envPrefix)
+		}
+	}
+	return hr, nil
+}
+
+// String formats the rule as with %+v, but hides any values read from the environment
+func (hr HeaderRule) String() string {
+	return fmt.Sprintf("%+v", headerRule(hr.redacted()))
+}
+
+// GoString formats the rule as with %#v, but hides any values read from the environment,
+// so that configuration can be logged without leaking secrets
+func (hr HeaderRule) GoString() string {
+	return strings.Replace(fmt.Sprintf("%#v", headerRule(hr.redacted())), "headerRule", "HeaderRule", 1)
+}
+
+// headerRule has the fields of HeaderRule, but formats as a plain struct
+type headerRule HeaderRule
+
+// redacted copies the rule, replacing any values read from the environment
+func (hr HeaderRule) redacted() HeaderRule {
+	if len(hr.Env) == 0 {
+		return hr
+	}
+	env := make(map[string]string)
+	for p := range hr.Env {
+		env[p] = redactedValue
+	}
+	hr.Env = env
+	return hr
+}
+
+// envPrefix marks a variable in a header value as to be read from the environment
+const envPrefix = "env:"
+
+// headerActions lists all the actions a header rule may be configured with
+func headerActions() []string {
+	return []string{HeaderSet, HeaderAdd, HeaderRemove, HeaderRename}
+}
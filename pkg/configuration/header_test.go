@@ -0,0 +1,60 @@
+package configuration
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestHeaderRuleReadsEnvironmentVariablesInValue(t *testing.T) {
+	t.Setenv("FERP_TEST_TOKEN", "secret")
+	hr, err := validateHeaderRule(HeaderRule{Action: "set", Name: "Authorization", Value: "Bearer {env:FERP_TEST_TOKEN}"})
+	if err != nil {
+		t.Fatalf("Failed to validate header rule: %s", err)
+	}
+	if hr.Value != "Bearer {env:FERP_TEST_TOKEN}" {
+		t.Errorf("Header rule has value '%s', expected 'Bearer {env:FERP_TEST_TOKEN}'", hr.Value)
+	}
+	if hr.Env["env:FERP_TEST_TOKEN"] != "secret" {
+		t.Errorf("Header rule has environment %#v, expected 'secret' for env:FERP_TEST_TOKEN", hr.Env)
+	}
+}
+
+func TestFormattedHeaderRuleHidesValuesFromEnvironment(t *testing.T) {
+	t.Setenv("FERP_TEST_TOKEN", "secret")
+	hr, err := validateHeaderRule(HeaderRule{Action: "set", Name: "Authorization", Value: "Bearer {env:FERP_TEST_TOKEN}"})
+	if err != nil {
+		t.Fatalf("Failed to validate header rule: %s", err)
+	}
+	i := Incoming{Path: "/api", Headers: HeaderRules{Request: []HeaderRule{hr}}}
+	for _, f := range []string{"%v", "%+v", "%#v"} {
+		s := fmt.Sprintf(f, i)
+		if strings.Contains(s, "secret") || !strings.Contains(s, "FERP_TEST_TOKEN") {
+			t.Errorf("Formatted incoming with %s as %s, expected environment variable but not its value", f, s)
+		}
+	}
+}
+
+func TestHeaderRuleRetainsRequestVariablesInValue(t *testing.T) {
+	hr, err := validateHeaderRule(HeaderRule{Action: "add", Name: "Forwarded", Value: "for={client-ip}"})
+	if err != nil {
+		t.Fatalf("Failed to validate header rule: %s", err)
+	}
+	if hr.Value != "for={client-ip}" {
+		t.Errorf("Header rule has value '%s', expected 'for={client-ip}'", hr.Value)
+	}
+}
+
+func TestInvalidHeaderRulesAreInvalid(t *testing.T) {
+	for _, hr := range []HeaderRule{
+		{Action: "replace", Name: "X-Foo"},
+		{Action: "set", Value: "foo"},
+		{Action: "rename", Name: "X-Foo"},
+		{Action: "set", Name: "X-Foo", Value: "{not-a-variable}"},
+		{Action: "set", Name: "X-Foo", Value: "{env:FERP_TEST_NOT_SET}"},
+	} {
+		if _, err := validateHeaderRule(hr); err == nil {
+			t.Errorf("Validated header rule %+v, but should not be possible", hr)
+		}
+	}
+}
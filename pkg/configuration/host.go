@@ -0,0 +1,59 @@
+package configuration
+
+import (
+	"fmt"
+
+	"github.com/snasphysicist/ferp/v2/pkg/functional"
+)
+
+// The policies for choosing the Host header sent to a downstream
+const (
+	// HostDownstream uses the downstream's host and port
+	HostDownstream = "downstream"
+	// HostPreserve uses the Host header the client sent to the proxy
+	HostPreserve = "preserve"
+	// HostFixed uses the configured value
+	HostFixed = "fixed"
+)
+
+// validateHostHeaders sets the default policy (downstream) for and validates
+// the host header configuration of all downstreams, returning an error
+// summarising which (if any) are invalid
+func validateHostHeaders(c Configuration) (Configuration, error) {
+	ds := make([]Downstream, 0)
+	errs := make([]error, 0)
+	for _, d := range c.Downstreams {
+		hh, err := validateHostHeader(d.HostHeader)
+		if err != nil {
+			err = fmt.Errorf("downstream %s: %s", d.Target, err)
+		}
+		errs = append(errs, err)
+		d.HostHeader = hh
+		ds = append(ds, d)
+	}
+	c.Downstreams = ds
+	return c, joinNonNilErrors(errs, ", ", "invalid host headers: %s")
+}
+
+// validateHostHeader sets the default policy if none is configured,
+// then checks that the policy is known and has a value if fixed
+func validateHostHeader(hh HostHeader) (HostHeader, error) {
+	if hh.Policy == "" {
+		hh.Policy = HostDownstream
+	}
+	if !functional.Contains(hostPolicies(), hh.Policy) {
+		return hh, fmt.Errorf("host header policy '%s' must be one of %v", hh.Policy, hostPolicies())
+	}
+	if hh.Policy == HostFixed && hh.Value == "" {
+		return hh, fmt.Errorf("host header policy %s requires a value", hh.Policy)
+	}
+	if hh.Policy != HostFixed && hh.Value != "" {
+		return hh, fmt.Errorf("host header policy %s does not use a value, got '%s'", hh.Policy, hh.Value)
+	}
+	return hh, nil
+}
+
+// hostPolicies lists all the policies for the Host header which may be configured
+func hostPolicies() []string {
+	return []string{HostDownstream, HostPreserve, HostFixed}
+}
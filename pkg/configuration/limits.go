@@ -0,0 +1,64 @@
+package configuration
+
+import "fmt"
+
+// DefaultBodyTooLarge is the message sent with the 413 response if none is configured
+const DefaultBodyTooLarge = "413: request body too large"
+
+// validateLimits sets defaults for and validates the limits of all servers and all their incomings,
+// populating any limits not set on an incoming from its server, returning an error
+// summarising which (if any) are invalid
+func validateLimits(c Configuration) (Configuration, error) {
+	c, errs := updateServers(c, func(s Server) (Server, error) {
+		l, is, err := validateServerLimits(s.Limits, s.Incoming)
+		s.Limits = l
+		s.Incoming = is
+		return s, err
+	})
+	return c, joinNonNilErrors(errs, ", ", "invalid limits: %s")
+}
+
+// validateServerLimits validates the limits of a server and those of its incomings,
+// the latter falling back to those of the server where not set
+func validateServerLimits(l Limits, is []Incoming) (Limits, []Incoming, error) {
+	if l.BodyTooLarge == "" {
+		l.BodyTooLarge = DefaultBodyTooLarge
+	}
+	errs := []error{validateLimit(l)}
+	iswl := make([]Incoming, 0)
+	for _, i := range is {
+		err := validateLimit(i.Limits)
+		if i.Limits.MaxHeaderBytes != 0 || i.Limits.MaxHeaders != 0 {
+			err = fmt.Errorf("max-header-bytes and max-headers can only be set on the server")
+		}
+		if err != nil {
+			err = fmt.Errorf("incoming %s: %s", i.Path, err)
+		}
+		errs = append(errs, err)
+		i.Limits = inheritLimits(i.Limits, l)
+		iswl = append(iswl, i)
+	}
+	return l, iswl, joinNonNilErrors(errs, ", ", "%s")
+}
+
+// validateLimit checks that none of the limits are negative
+func validateLimit(l Limits) error {
+	if l.MaxBodyBytes < 0 || l.MaxDuration < 0 || l.MaxHeaderBytes < 0 || l.MaxHeaders < 0 {
+		return fmt.Errorf("limits cannot be negative, got %+v", l)
+	}
+	return nil
+}
+
+// inheritLimits fills any of the body & duration limits which are not set from the fallback
+func inheritLimits(l Limits, fallback Limits) Limits {
+	if l.MaxBodyBytes == 0 {
+		l.MaxBodyBytes = fallback.MaxBodyBytes
+	}
+	if l.BodyTooLarge == "" {
+		l.BodyTooLarge = fallback.BodyTooLarge
+	}
+	if l.MaxDuration == 0 {
+		l.MaxDuration = fallback.MaxDuration
+	}
+	return l
+}
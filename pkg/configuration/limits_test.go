@@ -0,0 +1,27 @@
+package configuration
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIncomingLimitsFallBackToThoseOfTheServer(t *testing.T) {
+	server := Limits{MaxBodyBytes: 1024, MaxDuration: time.Minute}
+	_, is, err := validateServerLimits(server, []Incoming{{Path: "/upload", Limits: Limits{MaxBodyBytes: 4096}}})
+	if err != nil {
+		t.Fatalf("Failed to validate limits: %s", err)
+	}
+	expect := Limits{MaxBodyBytes: 4096, BodyTooLarge: DefaultBodyTooLarge, MaxDuration: time.Minute}
+	if is[0].Limits != expect {
+		t.Errorf("Incoming has limits %+v, expected %+v", is[0].Limits, expect)
+	}
+}
+
+func TestInvalidLimitsAreInvalid(t *testing.T) {
+	if _, _, err := validateServerLimits(Limits{MaxBodyBytes: -1}, []Incoming{}); err == nil {
+		t.Errorf("Validated negative server limit, but should not be possible")
+	}
+	if _, _, err := validateServerLimits(Limits{}, []Incoming{{Limits: Limits{MaxHeaders: 10}}}); err == nil {
+		t.Errorf("Validated header limit on incoming, but should not be possible")
+	}
+}
@@ -0,0 +1,65 @@
+package configuration
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// UnixPrefix marks a listen address as the path of a unix socket
+const UnixPrefix = "unix:"
+
+// validateListens validates the listen addresses of all servers, populating
+// the permissions of unix sockets, returning an error summarising which (if any) are invalid
+func validateListens(c Configuration) (Configuration, error) {
+	c, errs := updateServers(c, func(s Server) (Server, error) {
+		ls, err := validateListenAddresses(s.Listen)
+		s.Listen = ls
+		return s, err
+	})
+	return c, joinNonNilErrors(errs, ", ", "invalid listen addresses: %s")
+}
+
+// validateListenAddresses validates each of the listen addresses
+func validateListenAddresses(ls []Listen) ([]Listen, error) {
+	vls := make([]Listen, 0)
+	errs := make([]error, 0)
+	for _, l := range ls {
+		vl, err := validateListen(l)
+		errs = append(errs, err)
+		vls = append(vls, vl)
+	}
+	return vls, joinNonNilErrors(errs, ", ", "%s")
+}
+
+// validateListen checks that the address is a unix socket path or a host & port,
+// and parses the mode of a unix socket into its permissions
+func validateListen(l Listen) (Listen, error) {
+	if !strings.HasPrefix(l.Address, UnixPrefix) {
+		if l.Mode != "" {
+			return l, fmt.Errorf("mode can only be set for a unix socket, not '%s'", l.Address)
+		}
+		_, port, err := net.SplitHostPort(l.Address)
+		if err != nil {
+			return l, fmt.Errorf("address '%s' must be host:port or %s/path: %s", l.Address, UnixPrefix, err)
+		}
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return l, fmt.Errorf("address '%s' has invalid port '%s'", l.Address, port)
+		}
+		return l, nil
+	}
+	if strings.TrimPrefix(l.Address, UnixPrefix) == "" {
+		return l, fmt.Errorf("address '%s' must include the path of the socket", l.Address)
+	}
+	if l.Mode == "" {
+		return l, nil
+	}
+	mode, err := strconv.ParseUint(l.Mode, 8, 32)
+	if err != nil || mode > 0777 {
+		return l, fmt.Errorf("mode '%s' of '%s' must be octal permissions, e.g. 0660", l.Mode, l.Address)
+	}
+	l.Permissions = os.FileMode(mode)
+	return l, nil
+}
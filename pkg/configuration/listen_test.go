@@ -0,0 +1,47 @@
+package configuration
+
+import (
+	"testing"
+)
+
+func TestUnixSocketModeParsedIntoPermissions(t *testing.T) {
+	l, err := validateListen(Listen{Address: "unix:/run/ferp.sock", Mode: "0660"})
+	if err != nil {
+		t.Fatalf("Failed to validate listen address: %s", err)
+	}
+	if l.Permissions != 0660 {
+		t.Errorf("Unix socket has permissions %o, expected 660", l.Permissions)
+	}
+}
+
+func TestValidListenAddressesAreValid(t *testing.T) {
+	for _, l := range []Listen{
+		{Address: "0.0.0.0:80"},
+		{Address: "10.0.0.1:8080"},
+		{Address: "[::]:443"},
+		{Address: "[::1]:8443"},
+		{Address: "localhost:8080"},
+		{Address: "unix:/run/ferp.sock"},
+	} {
+		if _, err := validateListen(l); err != nil {
+			t.Errorf("Failed to validate listen address %+v: %s", l, err)
+		}
+	}
+}
+
+func TestInvalidListenAddressesAreInvalid(t *testing.T) {
+	for _, l := range []Listen{
+		{Address: "0.0.0.0"},
+		{Address: "::1:8080"},
+		{Address: "localhost:http-alt"},
+		{Address: "localhost:70000"},
+		{Address: "unix:"},
+		{Address: "unix:/run/ferp.sock", Mode: "rw-rw----"},
+		{Address: "unix:/run/ferp.sock", Mode: "01777"},
+		{Address: "0.0.0.0:80", Mode: "0660"},
+	} {
+		if _, err := validateListen(l); err == nil {
+			t.Errorf("Validated listen address %+v, but should not be possible", l)
+		}
+	}
+}
@@ -8,50 +8,69 @@ import (
 	"github.com/mitchellh/mapstructure"
 	"github.com/snasphysicist/ferp/v2/pkg/functional"
 	"github.com/snasphysicist/ferp/v2/pkg/log"
+	"github.com/snasphysicist/ferp/v2/pkg/mapper"
 	"github.com/spf13/viper"
 )
 
-// Load attempts to load and validate the server configuration from the given path
-func Load(path string) (Configuration, error) {
-	err := readInConfiguration(path)
+// Load attempts to load and validate the server configuration from the given path,
+// creating path mappers from the registry, and logging with the logger
+func Load(path string, r *mapper.Registry, l log.Logger) (Configuration, error) {
+	err := readInConfiguration(path, l)
 	if err != nil {
 		return Configuration{}, err
 	}
 	c := Configuration{}
 	err = viper.Unmarshal(&c, func(c *mapstructure.DecoderConfig) { c.TagName = "config" })
 	if err != nil {
-		log.L().Errorf("Failed to deserialise configuration: %s", err)
+		l.Errorf("Failed to deserialise configuration: %s", err)
 		return Configuration{}, err
 	}
-	log.L().Infof("Loaded and deserialised configuration: %#v", c)
-	c, err = validate(c)
+	l.Infof("Loaded and deserialised configuration: %#v", c)
+	c, err = validate(c, r, l)
 	if err != nil {
-		log.L().Errorf("The configuration is not valid: %s", err)
+		l.Errorf("The configuration is not valid: %s", err)
 		return Configuration{}, err
 	}
+	l.Debugf("Validated configuration: %#v", c)
 	return c, nil
 }
 
 // readInConfiguration configures viper to target the configuration file and attempts to read it in
-func readInConfiguration(path string) error {
+func readInConfiguration(path string, l log.Logger) error {
 	directory := filepath.Dir(path)
 	viper.AddConfigPath(directory)
 	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
 	viper.SetConfigName(name)
-	log.L().Infof("Attempting to load configuration from directory %s, name %s", directory, name)
+	l.Infof("Attempting to load configuration from directory %s, name %s", directory, name)
 	err := viper.ReadInConfig()
 	if err != nil {
-		log.L().Errorf("Failed to read in configuration: %s", err)
+		l.Errorf("Failed to read in configuration: %s", err)
 	}
 	return nil
 }
 
-// validate ensures that all options provided in the configuration are valid
-func validate(c Configuration) (Configuration, error) {
-	c, pmErr := populatePathMappers(c)
+// validate ensures that all options provided in the configuration are valid,
+// creating path mappers from the registry, and logging with the logger
+func validate(c Configuration, r *mapper.Registry, l log.Logger) (Configuration, error) {
+	c, sErr := populateServers(c)
+	c, pmErr := populatePathMappers(c, r)
+	c, qErr := validateQueryRules(c)
+	c, hErr := validateHeaderRules(c)
+	c, hhErr := validateHostHeaders(c)
+	c, lErr := validateLimits(c)
+	c, tErr := validateTimeouts(c)
+	c, lsErr := validateListens(c)
+	c, sdErr := validateShutdown(c)
+	c, raErr := populateRunAs(c)
+	c, alErr := validateAccessLog(c)
+	c, lgErr := validateLogging(c)
+	c, riErr := validateRequestID(c)
 	c, dErr := populateDownstreams(c)
-	c, mrErr := populateMethodRouters(c)
-	err := joinNonNilErrors([]error{pmErr, dErr, mrErr}, ", ", "invalid configuration: %s")
+	c, mrErr := populateMethodRouters(c, l)
+	c, rdErr := validateRedirects(c)
+	c, rmErr := populateRedirectMaps(c)
+	err := joinNonNilErrors([]error{sErr, pmErr, qErr, hErr, hhErr, lErr, tErr, lsErr,
+		sdErr, raErr, alErr, lgErr, riErr, dErr, mrErr, rdErr, rmErr}, ", ", "invalid configuration: %s")
 	return c, err
 }
 
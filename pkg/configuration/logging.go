@@ -0,0 +1,42 @@
+package configuration
+
+import (
+	"fmt"
+
+	"github.com/snasphysicist/ferp/v2/pkg/functional"
+	"go.uber.org/zap/zapcore"
+)
+
+// The encodings in which the application log can be written
+const (
+	// LogJSON writes each entry as a JSON object on its own line
+	LogJSON = "json"
+	// LogConsole writes each entry as human readable text
+	LogConsole = "console"
+)
+
+// validateLogging sets the default level & encoding of the application log if they are not configured,
+// then checks that they are known and the sampling is not negative
+func validateLogging(c Configuration) (Configuration, error) {
+	l := c.Logging
+	errs := make([]error, 0)
+	if l.Level == "" {
+		l.Level = zapcore.InfoLevel.String()
+	}
+	zl, err := zapcore.ParseLevel(l.Level)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("unknown level '%s'", l.Level))
+	}
+	l.ZapLevel = zl
+	if l.Encoding == "" {
+		l.Encoding = LogJSON
+	}
+	if !functional.Contains([]string{LogJSON, LogConsole}, l.Encoding) {
+		errs = append(errs, fmt.Errorf("unknown encoding '%s'", l.Encoding))
+	}
+	if l.Sampling.Initial < 0 || l.Sampling.Thereafter < 0 {
+		errs = append(errs, fmt.Errorf("sampling cannot be negative, got %+v", l.Sampling))
+	}
+	c.Logging = l
+	return c, joinNonNilErrors(errs, ", ", "invalid logging: %s")
+}
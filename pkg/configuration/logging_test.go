@@ -0,0 +1,40 @@
+package configuration
+
+import (
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLoggingDefaultsWhenNotConfigured(t *testing.T) {
+	c, err := validateLogging(Configuration{})
+	if err != nil {
+		t.Fatalf("Failed to validate logging: %s", err)
+	}
+	if c.Logging.ZapLevel != zapcore.InfoLevel || c.Logging.Encoding != LogJSON {
+		t.Errorf("Logging is %+v, expected level info and encoding %s", c.Logging, LogJSON)
+	}
+}
+
+func TestLoggingLevelIsParsed(t *testing.T) {
+	c, err := validateLogging(Configuration{Logging: Logging{Level: "debug", Encoding: LogConsole}})
+	if err != nil {
+		t.Fatalf("Failed to validate logging: %s", err)
+	}
+	if c.Logging.ZapLevel != zapcore.DebugLevel {
+		t.Errorf("Logging level is %s, expected debug", c.Logging.ZapLevel)
+	}
+}
+
+func TestInvalidLoggingIsInvalid(t *testing.T) {
+	for _, l := range []Logging{
+		{Level: "verbose"},
+		{Encoding: "xml"},
+		{Sampling: LogSampling{Initial: -1}},
+		{Sampling: LogSampling{Thereafter: -1}},
+	} {
+		if _, err := validateLogging(Configuration{Logging: l}); err == nil {
+			t.Errorf("Validated logging %+v, but should not be possible", l)
+		}
+	}
+}
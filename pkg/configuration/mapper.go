@@ -6,13 +6,13 @@ import (
 	"github.com/snasphysicist/ferp/v2/pkg/mapper"
 )
 
-// populatePathMappers attempts to find and instantiate a path mapper
-// for all downstream path mapper configurations
-func populatePathMappers(c Configuration) (Configuration, error) {
+// populatePathMappers attempts to find and instantiate a chain of path mappers
+// from the registry for all downstream path mapper configurations
+func populatePathMappers(c Configuration, r *mapper.Registry) (Configuration, error) {
 	ds := make([]Downstream, 0)
 	errs := make([]error, 0)
 	for _, d := range c.Downstreams {
-		m, err := loadPathMapper(d.MapperData)
+		m, err := loadPathMapperChain(d.MapperData, r)
 		errs = append(errs, err)
 		d.Mapper = m
 		ds = append(ds, d)
@@ -22,20 +22,42 @@ func populatePathMappers(c Configuration) (Configuration, error) {
 	return c, err
 }
 
-// loadPathMapper attempts to find and instantiate a path mapper
-// matching the provided configuration map
-// TODO: all extensibility with custom path mappers added at "runtime"
-func loadPathMapper(c map[string]string) (pathMapper, error) {
-	mappers := []pathMapper{&mapper.Passthrough{}, &mapper.RemovePrefix{}}
+// loadPathMapperChain attempts to find in the registry and instantiate a path mapper for each
+// of the provided configuration maps, returning a chain applying them in order
+func loadPathMapperChain(cs []map[string]interface{}, r *mapper.Registry) (mapper.Chain, error) {
+	if len(cs) == 0 {
+		return mapper.Chain{}, fmt.Errorf("no path mapper configured")
+	}
+	steps := make([]mapper.PathMapper, 0)
 	errs := make([]error, 0)
-	for _, m := range mappers {
-		err := m.From(c)
-		if err == nil {
-			return m, nil
+	for i, c := range cs {
+		m, err := loadPathMapper(c, r)
+		if err != nil && len(cs) > 1 {
+			err = fmt.Errorf("step %d of %d in path mapper chain: %s", i+1, len(cs), err)
 		}
 		errs = append(errs, err)
+		if m != nil {
+			steps = append(steps, m)
+		}
+	}
+	return mapper.NewChainOf(steps...), joinNonNilErrors(errs, ", ", "%s")
+}
+
+// loadPathMapper instantiates the path mapper registered in the registry with the type
+// given in the configuration map and deserialises the configuration into it
+func loadPathMapper(c map[string]interface{}, r *mapper.Registry) (mapper.PathMapper, error) {
+	t, ok := c["type"].(string)
+	if !ok {
+		return nil, fmt.Errorf("path mapper configuration %#v has no (string) type field", c)
+	}
+	f, ok := r.Lookup(t)
+	if !ok {
+		return nil, fmt.Errorf("path mapper configuration %#v has unknown type '%s', must be one of %v",
+			c, t, r.Types())
+	}
+	m := f()
+	if err := m.From(c); err != nil {
+		return nil, err
 	}
-	jErr := joinNonNilErrors(errs, ", ",
-		fmt.Sprintf("no mapper matching configuration %#v (failed to match: %s)", c, "%s"))
-	return nil, jErr
+	return m, nil
 }
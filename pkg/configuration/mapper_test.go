@@ -0,0 +1,64 @@
+package configuration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/snasphysicist/ferp/v2/pkg/mapper"
+)
+
+func TestPathMapperChainErrorNamesMisconfiguredStep(t *testing.T) {
+	cs := []map[string]interface{}{
+		{"type": "remove-prefix", "prefix": "/foo"},
+		{"type": "add-prefix", "fixpre": "/bar"},
+	}
+	_, err := loadPathMapperChain(cs, mapper.NewRegistry())
+	if err == nil || !strings.HasPrefix(err.Error(), "step 2 of 2 in path mapper chain") {
+		t.Errorf("Expected error naming step 2 for %+v, got %v", cs, err)
+	}
+}
+
+func TestEmptyPathMapperChainIsInvalid(t *testing.T) {
+	_, err := loadPathMapperChain([]map[string]interface{}{}, mapper.NewRegistry())
+	if err == nil {
+		t.Errorf("Loaded empty path mapper chain, but should not be possible")
+	}
+}
+
+func TestLoadsCustomRegisteredPathMapperByType(t *testing.T) {
+	r := mapper.NewRegistry()
+	err := r.Register("test-custom", func() mapper.PathMapper { return &customMapper{} })
+	if err != nil {
+		t.Fatalf("Failed to register custom path mapper: %s", err)
+	}
+	m, err := loadPathMapper(map[string]interface{}{"type": "test-custom", "foo": "bar"}, r)
+	if err != nil {
+		t.Errorf("Failed to load custom path mapper: %s", err)
+	}
+	if cm, ok := m.(*customMapper); !ok || cm.Foo != "bar" {
+		t.Errorf("Loaded %#v for custom type, expected the registered mapper with foo bar", m)
+	}
+}
+
+func TestPathMapperWithUnknownTypeIsInvalid(t *testing.T) {
+	_, err := loadPathMapper(map[string]interface{}{"type": "not-registered"}, mapper.NewRegistry())
+	if err == nil || !strings.Contains(err.Error(), "unknown type 'not-registered'") {
+		t.Errorf("Expected unknown type to be reported, got error %v", err)
+	}
+}
+
+// customMapper is a path mapper defined outside the mapper package, which stores its foo field
+type customMapper struct {
+	Type string `config:"type"`
+	Foo  string `config:"foo"`
+}
+
+// Map implements mapper.PathMapper for customMapper
+func (customMapper) Map(from string) (string, bool) {
+	return from, true
+}
+
+// From implements mapper.PathMapper for customMapper
+func (m *customMapper) From(c map[string]interface{}) error {
+	return mapper.Decode(c, m)
+}
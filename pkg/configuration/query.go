@@ -0,0 +1,123 @@
+package configuration
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/snasphysicist/ferp/v2/pkg/functional"
+)
+
+// The actions a query rule can take on the query parameters of a forwarded request
+const (
+	// QuerySet sets the parameter to the value, replacing any existing values
+	QuerySet = "set"
+	// QueryAdd adds the value to any existing values of the parameter
+	QueryAdd = "add"
+	// QueryRemove removes the parameter
+	QueryRemove = "remove"
+	// QueryRename moves all values of the parameter to the parameter named by To
+	QueryRename = "rename"
+	// QueryDropAll removes all query parameters
+	QueryDropAll = "drop-all"
+)
+
+// validateQueryRules validates the query rules for all downstreams and incomings,
+// reading values from the environment where configured, and returning an error
+// summarising which rules (if any) are invalid
+func validateQueryRules(c Configuration) (Configuration, error) {
+	errs := make([]error, 0)
+	ds := make([]Downstream, 0)
+	for _, d := range c.Downstreams {
+		qrs, err := validateQueryRulesIn(d.Query)
+		errs = append(errs, err)
+		d.Query = qrs
+		ds = append(ds, d)
+	}
+	c.Downstreams = ds
+	c, serrs := updateServers(c, func(s Server) (Server, error) {
+		is, err := validateQueryRulesForIncomings(s.Incoming)
+		s.Incoming = is
+		return s, err
+	})
+	errs = append(errs, serrs...)
+	return c, joinNonNilErrors(errs, ", ", "invalid query rules: %s")
+}
+
+// validateQueryRulesForIncomings validates the query rules of each of the incomings
+func validateQueryRulesForIncomings(is []Incoming) ([]Incoming, error) {
+	vis := make([]Incoming, 0)
+	errs := make([]error, 0)
+	for _, i := range is {
+		qrs, err := validateQueryRulesIn(i.Query)
+		errs = append(errs, err)
+		i.Query = qrs
+		vis = append(vis, i)
+	}
+	return vis, joinNonNilErrors(errs, ", ", "%s")
+}
+
+// validateQueryRulesIn validates each of the query rules
+func validateQueryRulesIn(qrs []QueryRule) ([]QueryRule, error) {
+	vqrs := make([]QueryRule, 0)
+	errs := make([]error, 0)
+	for _, qr := range qrs {
+		vqr, err := validateQueryRule(qr)
+		errs = append(errs, err)
+		vqrs = append(vqrs, vqr)
+	}
+	return vqrs, joinNonNilErrors(errs, ", ", "%s")
+}
+
+// validateQueryRule checks that the rule has a known action and the fields that action needs,
+// reading the value from the environment if configured to, failing if that variable is not set
+func validateQueryRule(qr QueryRule) (QueryRule, error) {
+	if !functional.Contains(queryActions(), qr.Action) {
+		return qr, fmt.Errorf("query rule %+v has action '%s', must be one of %v", qr, qr.Action, queryActions())
+	}
+	if qr.Action != QueryDropAll && qr.Name == "" {
+		return qr, fmt.Errorf("query rule %+v has no name, required for action %s", qr, qr.Action)
+	}
+	if qr.Action == QueryRename && qr.To == "" {
+		return qr, fmt.Errorf("query rule %+v has no to, required for action %s", qr, qr.Action)
+	}
+	if (qr.Action == QuerySet || qr.Action == QueryAdd) && qr.Env != "" {
+		v, ok := os.LookupEnv(qr.Env)
+		if !ok {
+			return qr, fmt.Errorf("query rule for %s reads environment variable %s, which is not set",
+				qr.Name, qr.Env)
+		}
+		qr.Value = v
+	}
+	return qr, nil
+}
+
+// String formats the rule as with %+v, but hides any value read from the environment
+func (qr QueryRule) String() string {
+	return fmt.Sprintf("%+v", queryRule(qr.redacted()))
+}
+
+// GoString formats the rule as with %#v, but hides any value read from the environment,
+// so that configuration can be logged without leaking secrets
+func (qr QueryRule) GoString() string {
+	return strings.Replace(fmt.Sprintf("%#v", queryRule(qr.redacted())), "queryRule", "QueryRule", 1)
+}
+
+// queryRule has the fields of QueryRule, but formats as a plain struct
+type queryRule QueryRule
+
+// redacted copies the rule, replacing any value read from the environment
+func (qr QueryRule) redacted() QueryRule {
+	if qr.Env != "" && qr.Value != "" {
+		qr.Value = redactedValue
+	}
+	return qr
+}
+
+// redactedValue is formatted in place of values which are read from the environment
+const redactedValue = "<redacted>"
+
+// queryActions lists all the actions a query rule may be configured with
+func queryActions() []string {
+	return []string{QuerySet, QueryAdd, QueryRemove, QueryRename, QueryDropAll}
+}
@@ -0,0 +1,65 @@
+package configuration
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestQueryRuleReadsValueFromEnvironmentWhenConfigured(t *testing.T) {
+	t.Setenv("FERP_TEST_API_KEY", "secret")
+	qr, err := validateQueryRule(QueryRule{Action: "set", Name: "key", Env: "FERP_TEST_API_KEY"})
+	if err != nil {
+		t.Fatalf("Failed to validate query rule: %s", err)
+	}
+	if qr.Value != "secret" {
+		t.Errorf("Query rule has value '%s', expected 'secret' from environment", qr.Value)
+	}
+}
+
+func TestQueryRuleReadingUnsetEnvironmentVariableIsInvalid(t *testing.T) {
+	_, err := validateQueryRule(QueryRule{Action: "set", Name: "key", Env: "FERP_TEST_NOT_SET"})
+	if err == nil {
+		t.Errorf("Validated query rule reading unset environment variable, but should not be possible")
+	}
+}
+
+func TestQueryRulesMissingRequiredFieldsAreInvalid(t *testing.T) {
+	for _, qr := range []QueryRule{
+		{Action: "replace", Name: "key"},
+		{Action: "set", Value: "secret"},
+		{Action: "rename", Name: "q"},
+	} {
+		if _, err := validateQueryRule(qr); err == nil {
+			t.Errorf("Validated query rule %+v, but should not be possible", qr)
+		}
+	}
+}
+
+func TestDropAllQueryRuleNeedsNoName(t *testing.T) {
+	if _, err := validateQueryRule(QueryRule{Action: "drop-all"}); err != nil {
+		t.Errorf("Failed to validate drop-all query rule: %s", err)
+	}
+}
+
+func TestFormattedQueryRuleHidesValueFromEnvironment(t *testing.T) {
+	t.Setenv("FERP_TEST_API_KEY", "secret")
+	qr, err := validateQueryRule(QueryRule{Action: "set", Name: "key", Env: "FERP_TEST_API_KEY"})
+	if err != nil {
+		t.Fatalf("Failed to validate query rule: %s", err)
+	}
+	i := Incoming{Path: "/api", Query: []QueryRule{qr}}
+	for _, f := range []string{"%v", "%+v", "%#v"} {
+		s := fmt.Sprintf(f, i)
+		if strings.Contains(s, "secret") || !strings.Contains(s, "FERP_TEST_API_KEY") {
+			t.Errorf("Formatted incoming with %s as %s, expected environment variable but not its value", f, s)
+		}
+	}
+}
+
+func TestFormattedQueryRuleShowsConfiguredValue(t *testing.T) {
+	s := fmt.Sprintf("%#v", QueryRule{Action: "set", Name: "page", Value: "1"})
+	if s != `configuration.QueryRule{Action:"set", Name:"page", Value:"1", Env:"", To:""}` {
+		t.Errorf("Formatted query rule as %s, expected all fields", s)
+	}
+}
@@ -0,0 +1,100 @@
+package configuration
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/snasphysicist/ferp/v2/pkg/functional"
+	"github.com/snasphysicist/ferp/v2/pkg/pattern"
+)
+
+// The ways in which a redirect can treat the query string of the request it redirects
+const (
+	// QueryDrop discards the incoming query string, only the query string in the target is used
+	QueryDrop = "drop"
+	// QueryPreserve uses the incoming query string, replacing any query string in the target
+	QueryPreserve = "preserve"
+	// QueryMerge combines the query parameters of the target with the incoming ones
+	QueryMerge = "merge"
+)
+
+// validateRedirects sets defaults for and validates all redirects in the configuration,
+// returning an error summarising which (if any) are invalid
+func validateRedirects(c Configuration) (Configuration, error) {
+	c, errs := updateServers(c, func(s Server) (Server, error) {
+		rds, err := validateRedirectsIn(s.Redirects)
+		s.Redirects = rds
+		return s, err
+	})
+	return c, joinNonNilErrors(errs, ", ", "invalid redirects: %s")
+}
+
+// validateRedirectsIn sets defaults for and validates each of the provided redirects
+func validateRedirectsIn(rds []Redirect) ([]Redirect, error) {
+	vrds := make([]Redirect, 0)
+	errs := make([]error, 0)
+	for _, rd := range rds {
+		vrd, err := validateRedirect(rd)
+		errs = append(errs, err)
+		vrds = append(vrds, vrd)
+	}
+	return vrds, joinNonNilErrors(errs, ", ", "%s")
+}
+
+// validateRedirect sets the default status (302) and query handling (drop) if they
+// are not configured, then checks that the status, query handling and target are valid,
+// and that every placeholder in the target is captured by the from pattern
+func validateRedirect(rd Redirect) (Redirect, error) {
+	if rd.Status == 0 {
+		rd.Status = http.StatusFound
+	}
+	if rd.Query == "" {
+		rd.Query = QueryDrop
+	}
+	errs := make([]error, 0)
+	if !functional.Contains(redirectStatuses(), rd.Status) {
+		errs = append(errs, fmt.Errorf(
+			"redirect from '%s' has status %d, must be one of %v", rd.From, rd.Status, redirectStatuses()))
+	}
+	if !functional.Contains(queryHandlings(), rd.Query) {
+		errs = append(errs, fmt.Errorf(
+			"redirect from '%s' has query '%s', must be one of %v", rd.From, rd.Query, queryHandlings()))
+	}
+	if _, err := url.Parse(rd.To); err != nil {
+		errs = append(errs, fmt.Errorf("redirect from '%s' has invalid target: %s", rd.From, err))
+	}
+	captured := pattern.Captures(rd.From)
+	groups, err := pattern.Groups(rd.From)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("redirect from '%s' has invalid from: %s", rd.From, err))
+	}
+	for g := range groups {
+		captured = append(captured, g)
+	}
+	uncaptured := functional.Filter(pattern.Placeholders(rd.To),
+		func(p string) bool { return !functional.Contains(captured, p) })
+	if len(uncaptured) > 0 {
+		errs = append(errs, fmt.Errorf(
+			"redirect from '%s' to '%s' uses placeholders %v which are not captured in from "+
+				"(regexp groups must be named, e.g. (?P<name>...), to be used)",
+			rd.From, rd.To, uncaptured))
+	}
+	return rd, joinNonNilErrors(errs, ", ", "%s")
+}
+
+// redirectStatuses lists the status codes a redirect may be configured to respond with
+func redirectStatuses() []int {
+	return []int{
+		http.StatusMovedPermanently,
+		http.StatusFound,
+		http.StatusSeeOther,
+		http.StatusTemporaryRedirect,
+		http.StatusPermanentRedirect,
+	}
+}
+
+// queryHandlings lists the ways a redirect may be configured to handle the incoming query string
+func queryHandlings() []string {
+	return []string{QueryDrop, QueryPreserve, QueryMerge}
+}
@@ -0,0 +1,174 @@
+package configuration
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/snasphysicist/ferp/v2/pkg/functional"
+	"gopkg.in/yaml.v3"
+)
+
+// populateRedirectMaps loads the entries of all redirect maps in the configuration,
+// returning an error summarising which (if any) could not be loaded or are invalid
+func populateRedirectMaps(c Configuration) (Configuration, error) {
+	c, errs := updateServers(c, func(s Server) (Server, error) {
+		rms, err := loadRedirectMaps(s.RedirectMaps)
+		s.RedirectMaps = rms
+		return s, err
+	})
+	return c, joinNonNilErrors(errs, ", ", "invalid redirect maps: %s")
+}
+
+// loadRedirectMaps loads the entries of each of the provided redirect maps
+func loadRedirectMaps(rms []RedirectMap) ([]RedirectMap, error) {
+	lrms := make([]RedirectMap, 0)
+	errs := make([]error, 0)
+	for _, rm := range rms {
+		lrm, err := LoadRedirectMap(rm)
+		errs = append(errs, err)
+		lrms = append(lrms, lrm)
+	}
+	return lrms, joinNonNilErrors(errs, ", ", "%s")
+}
+
+// LoadRedirectMap reads the entries of the redirect map from its file, sets defaults for and
+// validates each of them, and checks that no path is redirected twice and that there are no loops
+func LoadRedirectMap(rm RedirectMap) (RedirectMap, error) {
+	es, err := readRedirectMapEntries(rm.File)
+	if err != nil {
+		return rm, fmt.Errorf("failed to read redirect map %s: %s", rm.File, err)
+	}
+	entries := make(map[string]Redirect)
+	errs := make([]error, 0)
+	for _, e := range es {
+		if e.Status == 0 {
+			e.Status = rm.Status
+		}
+		rd, err := validateRedirect(Redirect{From: e.From, To: e.To, Status: e.Status, Query: rm.Query})
+		errs = append(errs, err)
+		if !strings.HasPrefix(rd.From, "/") {
+			errs = append(errs, fmt.Errorf("redirect from '%s' must be a path starting with /", rd.From))
+		}
+		if _, ok := entries[rd.From]; ok {
+			errs = append(errs, fmt.Errorf("redirect from '%s' appears more than once", rd.From))
+		}
+		entries[rd.From] = rd
+	}
+	errs = append(errs, findRedirectLoops(entries)...)
+	rm.Entries = entries
+	return rm, joinNonNilErrors(errs, ", ",
+		fmt.Sprintf("invalid redirect map %s: %s", rm.File, "%s"))
+}
+
+// redirectMapEntry is a single redirect in a redirect map file
+type redirectMapEntry struct {
+	From   string `json:"from" yaml:"from"`
+	To     string `json:"to" yaml:"to"`
+	Status int    `json:"status" yaml:"status"`
+}
+
+// readRedirectMapEntries reads all entries from the redirect map file,
+// choosing how to parse it from the file's extension
+func readRedirectMapEntries(path string) ([]redirectMapEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	es := make([]redirectMapEntry, 0)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return readCSVRedirectMapEntries(f)
+	case ".json":
+		err = json.NewDecoder(f).Decode(&es)
+	case ".yaml", ".yml":
+		err = yaml.NewDecoder(f).Decode(&es)
+	default:
+		return nil, fmt.Errorf(
+			"unsupported extension '%s', must be one of .csv, .json, .yaml or .yml", filepath.Ext(path))
+	}
+	if err == io.EOF {
+		return es, nil
+	}
+	return es, err
+}
+
+// readCSVRedirectMapEntries reads entries from CSV with one entry per line
+// (from,to or from,to,status), ignoring lines starting with #
+func readCSVRedirectMapEntries(r io.Reader) ([]redirectMapEntry, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	es := make([]redirectMapEntry, 0)
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return es, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		if len(record) != 2 && len(record) != 3 {
+			return nil, fmt.Errorf("line %d has %d fields, expected from,to or from,to,status",
+				line, len(record))
+		}
+		e := redirectMapEntry{From: record[0], To: record[1]}
+		if len(record) == 3 {
+			e.Status, err = strconv.Atoi(record[2])
+			if err != nil {
+				return nil, fmt.Errorf("line %d has invalid status '%s'", line, record[2])
+			}
+		}
+		es = append(es, e)
+	}
+}
+
+// findRedirectLoops follows the chain of redirects from each entry, returning
+// an error for each distinct loop found (only redirects to paths are followed)
+func findRedirectLoops(entries map[string]Redirect) []error {
+	froms := make([]string, 0)
+	for from := range entries {
+		froms = append(froms, from)
+	}
+	sort.Strings(froms)
+	errs := make([]error, 0)
+	for _, from := range froms {
+		chain := []string{from}
+		for next := redirectedPath(entries[from].To); next != ""; next = redirectedPath(entries[next].To) {
+			if _, ok := entries[next]; !ok {
+				break
+			}
+			if functional.Contains(chain, next) {
+				// only report a loop once, when starting from its (alphabetically) first path
+				earlier := functional.Filter(chain, func(p string) bool { return p < from })
+				if next == from && len(earlier) == 0 {
+					errs = append(errs, fmt.Errorf("redirect loop %s -> %s",
+						strings.Join(chain, " -> "), next))
+				}
+				break
+			}
+			chain = append(chain, next)
+		}
+	}
+	return errs
+}
+
+// redirectedPath returns the path redirected to, or an empty string
+// if the target is on another host and so cannot be part of a loop
+func redirectedPath(to string) string {
+	u, err := url.Parse(to)
+	if err != nil || u.Host != "" {
+		return ""
+	}
+	return u.Path
+}
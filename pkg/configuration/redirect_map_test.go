@@ -0,0 +1,73 @@
+package configuration
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadsRedirectMapFromEachSupportedFormat(t *testing.T) {
+	for name, content := range map[string]string{
+		"map.csv":  "/a,/b\n/c,/d,301\n",
+		"map.json": `[{"from": "/a", "to": "/b"}, {"from": "/c", "to": "/d", "status": 301}]`,
+		"map.yaml": "- from: /a\n  to: /b\n- from: /c\n  to: /d\n  status: 301\n",
+	} {
+		rm, err := LoadRedirectMap(RedirectMap{File: writeTemporaryFile(t, name, content)})
+		if err != nil {
+			t.Errorf("Failed to load redirect map %s: %s", name, err)
+			continue
+		}
+		if rm.Entries["/a"].To != "/b" || rm.Entries["/a"].Status != 302 {
+			t.Errorf("From %s loaded %+v for /a, expected redirect to /b with 302", name, rm.Entries["/a"])
+		}
+		if rm.Entries["/c"].To != "/d" || rm.Entries["/c"].Status != 301 {
+			t.Errorf("From %s loaded %+v for /c, expected redirect to /d with 301", name, rm.Entries["/c"])
+		}
+	}
+}
+
+func TestRedirectMapWithDuplicateEntriesIsInvalid(t *testing.T) {
+	f := writeTemporaryFile(t, "map.csv", "/a,/b\n/c,/d\n/a,/e\n")
+	_, err := LoadRedirectMap(RedirectMap{File: f})
+	if err == nil || !strings.Contains(err.Error(), "'/a' appears more than once") {
+		t.Errorf("Expected duplicate /a to be reported, got error %v", err)
+	}
+}
+
+func TestRedirectMapWithLoopIsInvalid(t *testing.T) {
+	f := writeTemporaryFile(t, "map.csv", "/a,/b\n/b,/c?foo=bar\n/c,/a\n/d,/a\n")
+	_, err := LoadRedirectMap(RedirectMap{File: f})
+	if err == nil || !strings.Contains(err.Error(), "redirect loop /a -> /b -> /c -> /a") {
+		t.Errorf("Expected loop /a -> /b -> /c -> /a to be reported, got error %v", err)
+	}
+	if strings.Count(err.Error(), "redirect loop") != 1 {
+		t.Errorf("Expected loop to be reported exactly once, got error %v", err)
+	}
+}
+
+func TestRedirectMapWithChainToOtherHostIsValid(t *testing.T) {
+	f := writeTemporaryFile(t, "map.csv", "/a,/b\n/b,https://example.com/a\n")
+	_, err := LoadRedirectMap(RedirectMap{File: f})
+	if err != nil {
+		t.Errorf("Expected redirect map to be valid, got error %s", err)
+	}
+}
+
+func TestRedirectMapWithInvalidStatusIsInvalid(t *testing.T) {
+	f := writeTemporaryFile(t, "map.csv", "/a,/b,200\n")
+	_, err := LoadRedirectMap(RedirectMap{File: f})
+	if err == nil {
+		t.Errorf("Loaded redirect map with status 200, but should not be possible")
+	}
+}
+
+// writeTemporaryFile writes the content to a file with the given name
+// in a temporary directory, returning the path to the file
+func writeTemporaryFile(t *testing.T, name string, content string) string {
+	p := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write temporary file %s: %s", p, err)
+	}
+	return p
+}
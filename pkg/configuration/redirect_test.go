@@ -0,0 +1,30 @@
+package configuration
+
+import (
+	"testing"
+)
+
+func TestRedirectPlaceholdersMayUseCapturedParametersAndNamedGroups(t *testing.T) {
+	for from, to := range map[string]string{
+		"/blog/{year:[0-9]+}/{slug}": "/posts/{slug}?year={year}",
+		"/old/*":                     "/new/{*}",
+		"/archive/{date:(?P<year>[0-9]{4})-(?P<month>[0-9]{2})}": "/archive/{year}/{month}?date={date}",
+	} {
+		if _, err := validateRedirect(Redirect{From: from, To: to}); err != nil {
+			t.Errorf("Failed to validate redirect from '%s' to '%s': %s", from, to, err)
+		}
+	}
+}
+
+func TestRedirectPlaceholdersMustBeCaptured(t *testing.T) {
+	for from, to := range map[string]string{
+		"/blog/{slug}":                    "/posts/{slug}?year={year}",
+		"/archive/{date:([0-9]{4})-.*}":   "/archive/{1}",
+		"/archive/{date:(?P<year>[0-9}":   "/archive/{year}",
+		"/archive/{date:(?P<date>[0-9])}": "/archive/{date}",
+	} {
+		if _, err := validateRedirect(Redirect{From: from, To: to}); err == nil {
+			t.Errorf("Validated redirect from '%s' to '%s', but should not be possible", from, to)
+		}
+	}
+}
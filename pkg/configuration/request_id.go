@@ -0,0 +1,22 @@
+package configuration
+
+import (
+	"fmt"
+
+	"github.com/snasphysicist/ferp/v2/pkg/request"
+)
+
+// RequestIDHeader is the default header in which requests are identified
+const RequestIDHeader = "X-Request-ID"
+
+// validateRequestID sets the default request ID header if it is not configured,
+// then checks that it can be used as the name of a header
+func validateRequestID(c Configuration) (Configuration, error) {
+	if c.RequestID.Header == "" {
+		c.RequestID.Header = RequestIDHeader
+	}
+	if !request.ValidHeaderName(c.RequestID.Header) {
+		return c, fmt.Errorf("invalid request id: header '%s' is not a valid header name", c.RequestID.Header)
+	}
+	return c, nil
+}
@@ -0,0 +1,33 @@
+package configuration
+
+import (
+	"testing"
+)
+
+func TestRequestIDHeaderDefaults(t *testing.T) {
+	c, err := validateRequestID(Configuration{})
+	if err != nil {
+		t.Fatalf("Failed to validate empty request id: %s", err)
+	}
+	if c.RequestID.Header != RequestIDHeader {
+		t.Errorf("Request id header is '%s', expected '%s'", c.RequestID.Header, RequestIDHeader)
+	}
+}
+
+func TestRequestIDHeaderIsKeptIfConfigured(t *testing.T) {
+	c, err := validateRequestID(Configuration{RequestID: RequestID{Header: "X-Correlation-ID"}})
+	if err != nil {
+		t.Fatalf("Failed to validate request id: %s", err)
+	}
+	if c.RequestID.Header != "X-Correlation-ID" {
+		t.Errorf("Request id header is '%s', expected 'X-Correlation-ID'", c.RequestID.Header)
+	}
+}
+
+func TestInvalidRequestIDHeadersAreInvalid(t *testing.T) {
+	for _, h := range []string{"X Request ID", "X-Request-ID:", "X-Request-ID\n"} {
+		if _, err := validateRequestID(Configuration{RequestID: RequestID{Header: h}}); err == nil {
+			t.Errorf("Validated request id header '%s', but should not be possible", h)
+		}
+	}
+}
@@ -5,36 +5,31 @@ import (
 	"github.com/snasphysicist/ferp/v2/pkg/log"
 )
 
-// populateMethodRouters adds method routers for all configured redirects and forwarded routes
-func populateMethodRouters(c Configuration) (Configuration, error) {
-	ii, iiErr := populateMethodRoutersForIncomings(c.HTTP.Incoming)
-	c.HTTP.Incoming = ii
-	ird, irdErr := populateMethodRoutersForRedirects(c.HTTP.Redirects)
-	c.HTTP.Redirects = ird
-	si, siErr := populateMethodRoutersForIncomings(c.HTTPS.Incoming)
-	c.HTTPS.Incoming = si
-	srd, srdErr := populateMethodRoutersForRedirects(c.HTTPS.Redirects)
-	c.HTTPS.Redirects = srd
-	err := joinNonNilErrors([]error{iiErr, irdErr, siErr, srdErr}, ", ", "invalid methods: %s")
+// populateMethodRouters adds method routers for all configured redirects and forwarded routes,
+// logging those found with the logger
+func populateMethodRouters(c Configuration, l log.Logger) (Configuration, error) {
+	c, errs := updateServers(c, func(s Server) (Server, error) {
+		is, iErr := populateMethodRoutersForIncomings(s.Incoming, l)
+		s.Incoming = is
+		rds, rdErr := populateMethodRoutersForRedirects(s.Redirects, l)
+		s.Redirects = rds
+		return s, joinNonNilErrors([]error{iErr, rdErr}, ", ", "%s")
+	})
+	err := joinNonNilErrors(errs, ", ", "invalid methods: %s")
 	return c, err
 }
 
 // populateMethodRoutersForIncomings populates the method routers fields in the provided
 // incomings, returning an error summarising which (if any) had invalid methods
-func populateMethodRoutersForIncomings(is []Incoming) ([]Incoming, error) {
+func populateMethodRoutersForIncomings(is []Incoming, l log.Logger) ([]Incoming, error) {
 	iswr := make([]Incoming, 0)
 	errs := make([]error, 0)
 	for _, i := range is {
 		mrs, err := findMethodRouters(i.Methods)
 		errs = append(errs, err)
-		iswr = append(iswr, Incoming{
-			Path:          i.Path,
-			Methods:       i.Methods,
-			MethodRouters: mrs,
-			Target:        i.Target,
-			Downstream:    i.Downstream,
-		})
-		log.L().Infof("For %+v, method routers %#v", i, mrs)
+		l.Infof("For %+v, method routers %#v", i, mrs)
+		i.MethodRouters = mrs
+		iswr = append(iswr, i)
 	}
 	err := joinNonNilErrors(errs, ", ", "invalid methods: %s")
 	return iswr, err
@@ -42,19 +37,15 @@ func populateMethodRoutersForIncomings(is []Incoming) ([]Incoming, error) {
 
 // populateMethodRoutersForRedirects populates the method routers fields in the provided
 // redirects, returning an error summarising which (if any) had invalid methods
-func populateMethodRoutersForRedirects(rds []Redirect) ([]Redirect, error) {
+func populateMethodRoutersForRedirects(rds []Redirect, l log.Logger) ([]Redirect, error) {
 	rdswr := make([]Redirect, 0)
 	errs := make([]error, 0)
 	for _, rd := range rds {
 		mrs, err := findMethodRouters(rd.Methods)
 		errs = append(errs, err)
-		rdswr = append(rdswr, Redirect{
-			From:          rd.From,
-			To:            rd.To,
-			Methods:       rd.Methods,
-			MethodRouters: mrs,
-		})
-		log.L().Infof("For %+v, method routers %#v", rd, mrs)
+		l.Infof("For %+v, method routers %#v", rd, mrs)
+		rd.MethodRouters = mrs
+		rdswr = append(rdswr, rd)
 	}
 	err := joinNonNilErrors(errs, ", ", "invalid methods: %s")
 	return rdswr, err
@@ -0,0 +1,56 @@
+package configuration
+
+import (
+	"fmt"
+	"os/user"
+	"strconv"
+)
+
+// populateRunAs looks up the ids of the user & group to run as, if configured
+func populateRunAs(c Configuration) (Configuration, error) {
+	ra := c.RunAs
+	if ra.User == "" && ra.Group == "" {
+		return c, nil
+	}
+	if ra.User == "" {
+		return c, fmt.Errorf("invalid run as: group '%s' set without a user", ra.Group)
+	}
+	u, err := lookupUser(ra.User)
+	if err != nil {
+		return c, fmt.Errorf("invalid run as: %w", err)
+	}
+	gid := u.Gid
+	if ra.Group != "" {
+		g, err := lookupGroup(ra.Group)
+		if err != nil {
+			return c, fmt.Errorf("invalid run as: %w", err)
+		}
+		gid = g.Gid
+	}
+	ra.UID, err = strconv.Atoi(u.Uid)
+	if err != nil {
+		return c, fmt.Errorf("invalid run as: user '%s' has non-numeric id '%s'", ra.User, u.Uid)
+	}
+	ra.GID, err = strconv.Atoi(gid)
+	if err != nil {
+		return c, fmt.Errorf("invalid run as: group has non-numeric id '%s'", gid)
+	}
+	c.RunAs = ra
+	return c, nil
+}
+
+// lookupUser finds the user by id if numeric, else by name
+func lookupUser(u string) (*user.User, error) {
+	if _, err := strconv.Atoi(u); err == nil {
+		return user.LookupId(u)
+	}
+	return user.Lookup(u)
+}
+
+// lookupGroup finds the group by id if numeric, else by name
+func lookupGroup(g string) (*user.Group, error) {
+	if _, err := strconv.Atoi(g); err == nil {
+		return user.LookupGroupId(g)
+	}
+	return user.LookupGroup(g)
+}
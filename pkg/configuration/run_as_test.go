@@ -0,0 +1,46 @@
+package configuration
+
+import (
+	"testing"
+)
+
+func TestRunAsIsNotRequired(t *testing.T) {
+	c, err := populateRunAs(Configuration{})
+	if err != nil {
+		t.Fatalf("Failed to populate empty run as: %s", err)
+	}
+	if c.RunAs != (RunAs{}) {
+		t.Errorf("Run as is %+v, expected it to be empty", c.RunAs)
+	}
+}
+
+func TestRunAsIdsArePopulatedFromNamesOrIds(t *testing.T) {
+	for _, ra := range []RunAs{
+		{User: "root"},
+		{User: "0"},
+		{User: "root", Group: "root"},
+		{User: "root", Group: "0"},
+	} {
+		c, err := populateRunAs(Configuration{RunAs: ra})
+		if err != nil {
+			t.Errorf("Failed to populate run as %+v: %s", ra, err)
+			continue
+		}
+		if c.RunAs.UID != 0 || c.RunAs.GID != 0 {
+			t.Errorf("Run as %+v populated with uid %d & gid %d, expected 0 & 0",
+				ra, c.RunAs.UID, c.RunAs.GID)
+		}
+	}
+}
+
+func TestInvalidRunAsIsInvalid(t *testing.T) {
+	for _, ra := range []RunAs{
+		{Group: "root"},
+		{User: "no-such-user-for-ferp"},
+		{User: "root", Group: "no-such-group-for-ferp"},
+	} {
+		if _, err := populateRunAs(Configuration{RunAs: ra}); err == nil {
+			t.Errorf("Populated run as %+v, but should not be possible", ra)
+		}
+	}
+}
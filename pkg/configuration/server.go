@@ -0,0 +1,90 @@
+package configuration
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/snasphysicist/ferp/v2/pkg/functional"
+)
+
+// The names of the servers configured by the http & https shorthands
+const (
+	ServerHTTP  = "http"
+	ServerHTTPS = "https"
+)
+
+// populateServers moves the servers configured by the http & https shorthands
+// (if configured) into the servers, then checks that every server has a unique
+// name and either both or neither TLS files
+func populateServers(c Configuration) (Configuration, error) {
+	ss := make([]Server, 0)
+	errs := make([]error, 0)
+	if !reflect.DeepEqual(c.HTTP, HTTP{}) {
+		ss = append(ss, Server{
+			Name:         ServerHTTP,
+			Port:         c.HTTP.Port,
+			Listen:       c.HTTP.Listen,
+			Redirects:    c.HTTP.Redirects,
+			RedirectMaps: c.HTTP.RedirectMaps,
+			Incoming:     c.HTTP.Incoming,
+			Limits:       c.HTTP.Limits,
+			Timeouts:     c.HTTP.Timeouts,
+		})
+	}
+	if !reflect.DeepEqual(c.HTTPS, HTTPS{}) {
+		s := Server{
+			Name:         ServerHTTPS,
+			Port:         c.HTTPS.Port,
+			Listen:       c.HTTPS.Listen,
+			TLS:          TLS{CertFile: c.HTTPS.CertFile, KeyFile: c.HTTPS.KeyFile},
+			Redirects:    c.HTTPS.Redirects,
+			RedirectMaps: c.HTTPS.RedirectMaps,
+			Incoming:     c.HTTPS.Incoming,
+			Limits:       c.HTTPS.Limits,
+			Timeouts:     c.HTTPS.Timeouts,
+		}
+		if s.TLS.CertFile == "" && Serves(s) {
+			errs = append(errs, fmt.Errorf("server %s requires a cert-file and key-file", s.Name))
+		}
+		ss = append(ss, s)
+	}
+	c.Servers = append(ss, c.Servers...)
+	c.HTTP = HTTP{}
+	c.HTTPS = HTTPS{}
+	names := make([]string, 0)
+	for _, s := range c.Servers {
+		if s.Name == "" {
+			errs = append(errs, fmt.Errorf("every server must have a name"))
+		}
+		if s.Name != "" && functional.Contains(names, s.Name) {
+			errs = append(errs, fmt.Errorf("server name '%s' appears more than once", s.Name))
+		}
+		if (s.TLS.CertFile == "") != (s.TLS.KeyFile == "") {
+			errs = append(errs, fmt.Errorf("server %s must have both or neither of cert-file and key-file", s.Name))
+		}
+		names = append(names, s.Name)
+	}
+	return c, joinNonNilErrors(errs, ", ", "invalid servers: %s")
+}
+
+// Serves checks whether the server has any routes or redirects, since otherwise it need not be started
+func Serves(s Server) bool {
+	return len(s.Incoming) != 0 || len(s.Redirects) != 0 || len(s.RedirectMaps) != 0
+}
+
+// updateServers applies the update to each server in the configuration, returning
+// the errors for each (nil if there were none) prefixed with the server's name
+func updateServers(c Configuration, update func(Server) (Server, error)) (Configuration, []error) {
+	ss := make([]Server, 0)
+	errs := make([]error, 0)
+	for _, s := range c.Servers {
+		us, err := update(s)
+		if err != nil {
+			err = fmt.Errorf("server %s: %s", s.Name, err)
+		}
+		errs = append(errs, err)
+		ss = append(ss, us)
+	}
+	c.Servers = ss
+	return c, errs
+}
@@ -0,0 +1,50 @@
+package configuration
+
+import (
+	"testing"
+)
+
+func TestShorthandServersComeBeforeNamedServers(t *testing.T) {
+	c, err := populateServers(Configuration{
+		Servers: []Server{{Name: "internal", Port: 8443}},
+		HTTP:    HTTP{Port: 80},
+		HTTPS:   HTTPS{Port: 443, CertFile: "cert.pem", KeyFile: "key.pem"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to populate servers: %s", err)
+	}
+	names := make([]string, 0)
+	for _, s := range c.Servers {
+		names = append(names, s.Name)
+	}
+	if len(names) != 3 || names[0] != ServerHTTP || names[1] != ServerHTTPS || names[2] != "internal" {
+		t.Errorf("Servers are %v, expected [http https internal]", names)
+	}
+	if c.Servers[1].TLS.CertFile != "cert.pem" || c.Servers[1].TLS.KeyFile != "key.pem" {
+		t.Errorf("Server https has TLS %+v, expected the files from the shorthand", c.Servers[1].TLS)
+	}
+}
+
+func TestShorthandServersNotAddedIfNotConfigured(t *testing.T) {
+	c, err := populateServers(Configuration{Servers: []Server{{Name: "internal", Port: 8443}}})
+	if err != nil {
+		t.Fatalf("Failed to populate servers: %s", err)
+	}
+	if len(c.Servers) != 1 {
+		t.Errorf("Servers are %+v, expected only internal", c.Servers)
+	}
+}
+
+func TestInvalidServersAreInvalid(t *testing.T) {
+	for _, c := range []Configuration{
+		{Servers: []Server{{Port: 80}}},
+		{Servers: []Server{{Name: "a", Port: 80}, {Name: "a", Port: 81}}},
+		{Servers: []Server{{Name: ServerHTTP, Port: 81}}, HTTP: HTTP{Port: 80}},
+		{Servers: []Server{{Name: "a", Port: 443, TLS: TLS{CertFile: "cert.pem"}}}},
+		{HTTPS: HTTPS{Port: 443, Incoming: []Incoming{{Path: "/"}}}},
+	} {
+		if _, err := populateServers(c); err == nil {
+			t.Errorf("Populated servers from %+v, but should not be possible", c)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package configuration
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultDrain is how long to wait for in-flight requests on shutdown if not configured
+const DefaultDrain = 30 * time.Second
+
+// validateShutdown sets the default drain duration if none is configured,
+// and checks that the durations are not negative and the readiness path is a path
+func validateShutdown(c Configuration) (Configuration, error) {
+	sd := c.Shutdown
+	errs := make([]error, 0)
+	if sd.Delay < 0 || sd.Drain < 0 {
+		errs = append(errs, fmt.Errorf("delay and drain cannot be negative, got %+v", sd))
+	}
+	if sd.ReadinessPath != "" && !strings.HasPrefix(sd.ReadinessPath, "/") {
+		errs = append(errs, fmt.Errorf("readiness path '%s' must start with /", sd.ReadinessPath))
+	}
+	if sd.Drain == 0 {
+		sd.Drain = DefaultDrain
+	}
+	c.Shutdown = sd
+	return c, joinNonNilErrors(errs, ", ", "invalid shutdown: %s")
+}
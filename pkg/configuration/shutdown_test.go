@@ -0,0 +1,28 @@
+package configuration
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShutdownDrainDefaultsWhenNotConfigured(t *testing.T) {
+	c, err := validateShutdown(Configuration{Shutdown: Shutdown{Delay: time.Second}})
+	if err != nil {
+		t.Fatalf("Failed to validate shutdown: %s", err)
+	}
+	if c.Shutdown.Drain != DefaultDrain {
+		t.Errorf("Shutdown drain is %s, expected the default %s", c.Shutdown.Drain, DefaultDrain)
+	}
+}
+
+func TestInvalidShutdownsAreInvalid(t *testing.T) {
+	for _, sd := range []Shutdown{
+		{Delay: -time.Second},
+		{Drain: -time.Second},
+		{ReadinessPath: "ready"},
+	} {
+		if _, err := validateShutdown(Configuration{Shutdown: sd}); err == nil {
+			t.Errorf("Validated shutdown %+v, but should not be possible", sd)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package configuration
+
+import (
+	"fmt"
+	"time"
+)
+
+// The timeouts of a server for which none are configured, the read & write timeouts
+// are not limited by default so as not to cut off long uploads & downloads
+const (
+	DefaultReadHeaderTimeout = 10 * time.Second
+	DefaultIdleTimeout       = 2 * time.Minute
+)
+
+// validateTimeouts sets defaults for and validates the timeouts of all servers
+// and all their incomings, returning an error summarising which (if any) are invalid
+func validateTimeouts(c Configuration) (Configuration, error) {
+	c, errs := updateServers(c, func(s Server) (Server, error) {
+		t, err := validateServerTimeouts(s.Timeouts, s.Incoming)
+		s.Timeouts = t
+		return s, err
+	})
+	return c, joinNonNilErrors(errs, ", ", "invalid timeouts: %s")
+}
+
+// validateServerTimeouts sets defaults for the read header & idle timeouts of the server
+// if they are not configured, and checks that its incomings only override the read & write timeouts
+func validateServerTimeouts(t Timeouts, is []Incoming) (Timeouts, error) {
+	errs := []error{validateTimeout(t)}
+	t.ReadHeader = withDefault(t.ReadHeader, DefaultReadHeaderTimeout)
+	t.Idle = withDefault(t.Idle, DefaultIdleTimeout)
+	for _, i := range is {
+		err := validateTimeout(i.Timeouts)
+		if i.Timeouts.ReadHeader != 0 || i.Timeouts.Idle != 0 {
+			err = fmt.Errorf("read-header and idle timeouts can only be set on the server")
+		}
+		if err != nil {
+			err = fmt.Errorf("incoming %s: %s", i.Path, err)
+		}
+		errs = append(errs, err)
+	}
+	return t, joinNonNilErrors(errs, ", ", "%s")
+}
+
+// validateTimeout checks that none of the timeouts are negative
+func validateTimeout(t Timeouts) error {
+	if t.ReadHeader < 0 || t.Read < 0 || t.Write < 0 || t.Idle < 0 {
+		return fmt.Errorf("timeouts cannot be negative, got %+v", t)
+	}
+	return nil
+}
+
+// withDefault returns the default if the timeout is not set
+func withDefault(t time.Duration, d time.Duration) time.Duration {
+	if t == 0 {
+		return d
+	}
+	return t
+}
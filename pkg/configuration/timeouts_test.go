@@ -0,0 +1,30 @@
+package configuration
+
+import (
+	"testing"
+	"time"
+)
+
+func TestServerTimeoutsNotConfiguredUseDefaults(t *testing.T) {
+	st, err := validateServerTimeouts(Timeouts{Write: time.Hour}, []Incoming{})
+	if err != nil {
+		t.Fatalf("Failed to validate timeouts: %s", err)
+	}
+	expect := Timeouts{
+		ReadHeader: DefaultReadHeaderTimeout,
+		Write:      time.Hour,
+		Idle:       DefaultIdleTimeout,
+	}
+	if st != expect {
+		t.Errorf("Server has timeouts %+v, expected %+v", st, expect)
+	}
+}
+
+func TestInvalidTimeoutsAreInvalid(t *testing.T) {
+	if _, err := validateServerTimeouts(Timeouts{Read: -time.Second}, []Incoming{}); err == nil {
+		t.Errorf("Validated negative server timeout, but should not be possible")
+	}
+	if _, err := validateServerTimeouts(Timeouts{}, []Incoming{{Timeouts: Timeouts{Idle: time.Second}}}); err == nil {
+		t.Errorf("Validated idle timeout on incoming, but should not be possible")
+	}
+}
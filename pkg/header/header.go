@@ -0,0 +1,123 @@
+package header
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/snasphysicist/ferp/v2/pkg/request"
+)
+
+// Rewriter changes a set of headers, possibly using values from the incoming request
+type Rewriter func(h http.Header, incoming *http.Request)
+
+// Apply applies each of the rewriters in order to the headers
+func Apply(h http.Header, rws []Rewriter, incoming *http.Request) {
+	for _, rw := range rws {
+		rw(h, incoming)
+	}
+}
+
+// Set sets the named header to the expanded template, replacing any existing values
+func Set(name string, value string, fixed map[string]string) Rewriter {
+	return func(h http.Header, incoming *http.Request) {
+		h.Set(name, Expand(value, incoming, fixed))
+	}
+}
+
+// Add adds the expanded template to any existing values of the named header
+func Add(name string, value string, fixed map[string]string) Rewriter {
+	return func(h http.Header, incoming *http.Request) {
+		h.Add(name, Expand(value, incoming, fixed))
+	}
+}
+
+// Remove removes all values of the named header
+func Remove(name string) Rewriter {
+	return func(h http.Header, _ *http.Request) {
+		h.Del(name)
+	}
+}
+
+// Rename moves all values of the named header to the end of the values of the header named by to
+func Rename(name string, to string) Rewriter {
+	return func(h http.Header, _ *http.Request) {
+		vs := h.Values(name)
+		if len(vs) == 0 {
+			return
+		}
+		h.Del(name)
+		for _, v := range vs {
+			h.Add(to, v)
+		}
+	}
+}
+
+// Expand replaces each {variable} in the template with its fixed value if it has one,
+// else its value for the incoming request, leaving any text in braces which is not
+// a known variable unchanged. Values are filled in one pass, so are never themselves expanded.
+func Expand(template string, incoming *http.Request, fixed map[string]string) string {
+	return placeholder.ReplaceAllStringFunc(template, func(p string) string {
+		name := placeholder.FindStringSubmatch(p)[1]
+		if f, ok := fixed[name]; ok {
+			return f
+		}
+		v, ok := variables()[name]
+		if !ok {
+			return p
+		}
+		return v(incoming)
+	})
+}
+
+// placeholder matches a {variable} in a template, capturing the variable name
+var placeholder = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// Variables lists, alphabetically, the names of all variables which can be used in templates
+func Variables() []string {
+	vs := make([]string, 0)
+	for v := range variables() {
+		vs = append(vs, v)
+	}
+	sort.Strings(vs)
+	return vs
+}
+
+// variables maps the name of each template variable to a function finding its value for a request
+func variables() map[string]func(*http.Request) string {
+	return map[string]func(*http.Request) string{
+		"client-ip":   clientIP,
+		"host":        func(r *http.Request) string { return r.Host },
+		"request-id":  requestID,
+		"route":       route,
+		"time-micros": func(*http.Request) string { return fmt.Sprint(time.Now().UnixMicro()) },
+	}
+}
+
+// clientIP finds the IP address of the client which sent the request
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// requestID finds the ID by which the request was identified, if it was
+func requestID(r *http.Request) string {
+	id, _ := request.IDFrom(r.Context())
+	return id.Value
+}
+
+// route finds the pattern of the route that matched the request, if any
+func route(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return ""
+	}
+	return rctx.RoutePattern()
+}
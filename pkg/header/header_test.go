@@ -0,0 +1,69 @@
+package header
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/snasphysicist/ferp/v2/pkg/request"
+)
+
+func TestExpandFillsVariablesFromIncomingRequest(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "http://example.com/users/123", http.NoBody)
+	r.RemoteAddr = "192.0.2.1:34567"
+	rctx := chi.NewRouteContext()
+	rctx.RoutePatterns = []string{"/users/{id}"}
+	ctx := request.WithID(r.Context(), request.ID{Header: "X-Request-ID", Value: "abc-123"})
+	r = r.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+
+	for template, expect := range map[string]string{
+		"fixed":                       "fixed",
+		"{client-ip}":                 "192.0.2.1",
+		"for={client-ip};host={host}": "for=192.0.2.1;host=example.com",
+		"{request-id}":                "abc-123",
+		"{route}":                     "/users/{id}",
+		"{unknown}":                   "{unknown}",
+	} {
+		e := Expand(template, r, nil)
+		if e != expect {
+			t.Errorf("Expanded '%s' to '%s', expected '%s'", template, e, expect)
+		}
+	}
+}
+
+func TestExpandFillsFixedValuesWithoutExpandingThem(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "http://example.com/", http.NoBody)
+	r = r.WithContext(request.WithID(r.Context(), request.ID{Header: "X-Request-ID", Value: "abc-123"}))
+	e := Expand("Bearer {env:TOKEN} {request-id}", r, map[string]string{"env:TOKEN": "{request-id}"})
+	if e != "Bearer {request-id} abc-123" {
+		t.Errorf("Expanded template to '%s', expected fixed value to be filled in unchanged", e)
+	}
+}
+
+func TestRewritersApplyInOrder(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "http://example.com/", http.NoBody)
+	h := http.Header{
+		"Cookie":   []string{"session=secret"},
+		"X-Old":    []string{"one", "two"},
+		"X-Exists": []string{"zero"},
+		"X-Keep":   []string{"keep"},
+	}
+	Apply(h, []Rewriter{
+		Remove("Cookie"),
+		Rename("X-Old", "X-Exists"),
+		Set("X-Set", "set", nil),
+		Add("X-Keep", "added", nil),
+		Set("X-Set", "set again", nil),
+	}, r)
+	expect := http.Header{
+		"X-Exists": []string{"zero", "one", "two"},
+		"X-Keep":   []string{"keep", "added"},
+		"X-Set":    []string{"set again"},
+	}
+	if !reflect.DeepEqual(h, expect) {
+		t.Errorf("Rewrote headers to %#v, expected %#v", h, expect)
+	}
+}
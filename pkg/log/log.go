@@ -1,32 +1,52 @@
 package log
 
 import (
+	"context"
+
 	"go.uber.org/zap"
 )
 
-// logger is the global logger instance
-// TODO: avoid package level mutable state
-var logger *zap.SugaredLogger
-
-// Initialise initialises the logger
-func Initialise() (func(), error) {
-	l, err := zap.NewProduction()
-	if err != nil {
-		return func() {}, err
-	}
-	logger = l.Sugar()
-	return func() { _ = l.Sync() }, nil
-}
-
-// L provides safe access to the global logger
-func L() Logger {
-	return logger
-}
-
-// Logger is returned from this package instead of the full logger
+// Logger is passed around the application instead of the full logger
 // to limit the operations client code is allowed to take on it
 type Logger interface {
 	Errorf(string, ...interface{})
 	Infof(string, ...interface{})
 	Debugf(string, ...interface{})
+	With(keysAndValues ...interface{}) Logger // adds the fields to every entry logged
+}
+
+// New wraps the zap logger as a Logger
+func New(l *zap.Logger) Logger {
+	return sugared{SugaredLogger: l.Sugar()}
+}
+
+// Nop returns a logger which discards everything
+func Nop() Logger {
+	return New(zap.NewNop())
+}
+
+// sugared implements Logger with a zap sugared logger
+type sugared struct {
+	*zap.SugaredLogger
+}
+
+// With implements Logger for sugared
+func (s sugared) With(keysAndValues ...interface{}) Logger {
+	return sugared{SugaredLogger: s.SugaredLogger.With(keysAndValues...)}
+}
+
+// loggerKey is the key under which the logger for a request is stored in its context
+type loggerKey struct{}
+
+// WithLogger returns a copy of the context carrying the logger
+func WithLogger(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// From returns the logger carried by the context, or one which discards everything if there is none
+func From(ctx context.Context) Logger {
+	if l, ok := ctx.Value(loggerKey{}).(Logger); ok {
+		return l
+	}
+	return Nop()
 }
@@ -0,0 +1,64 @@
+package log
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Options configures a root logger
+type Options struct {
+	Level      zapcore.Level
+	Encoding   string   // json or console
+	Outputs    []string // standard error if empty
+	Initial    int      // entries with the same message logged per second before sampling, 0 for the default
+	Thereafter int      // after which every nth entry is logged, 0 for the default
+}
+
+// Root is a logger built from options, from which all others are derived,
+// whose level can be changed while it is in use
+type Root struct {
+	Logger
+	zap        *zap.Logger
+	level      zap.AtomicLevel
+	configured zapcore.Level
+}
+
+// Build builds a root logger from the options
+func Build(o Options) (*Root, error) {
+	c := zap.NewProductionConfig()
+	if o.Encoding == "console" {
+		c.EncoderConfig = zap.NewDevelopmentEncoderConfig()
+	}
+	c.Encoding = o.Encoding
+	c.Level = zap.NewAtomicLevelAt(o.Level)
+	if len(o.Outputs) > 0 {
+		c.OutputPaths = o.Outputs
+	}
+	if o.Initial > 0 {
+		c.Sampling.Initial = o.Initial
+	}
+	if o.Thereafter > 0 {
+		c.Sampling.Thereafter = o.Thereafter
+	}
+	l, err := c.Build()
+	if err != nil {
+		return nil, err
+	}
+	return &Root{Logger: New(l), zap: l, level: c.Level, configured: o.Level}, nil
+}
+
+// ToggleDebug switches the logger to the debug level, or back to
+// the configured level if it is already at debug, returning the new level
+func (r *Root) ToggleDebug() zapcore.Level {
+	if r.level.Level() == zapcore.DebugLevel {
+		r.level.SetLevel(r.configured)
+	} else {
+		r.level.SetLevel(zapcore.DebugLevel)
+	}
+	return r.level.Level()
+}
+
+// Sync flushes any buffered entries
+func (r *Root) Sync() {
+	_ = r.zap.Sync()
+}
@@ -0,0 +1,20 @@
+package log
+
+import (
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestToggleDebugSwitchesToDebugAndBack(t *testing.T) {
+	r, err := Build(Options{Level: zapcore.WarnLevel, Encoding: "console"})
+	if err != nil {
+		t.Fatalf("Failed to build logger: %s", err)
+	}
+	if l := r.ToggleDebug(); l != zapcore.DebugLevel {
+		t.Errorf("Toggled to %s, expected debug", l)
+	}
+	if l := r.ToggleDebug(); l != zapcore.WarnLevel {
+		t.Errorf("Toggled back to %s, expected the configured warn", l)
+	}
+}
@@ -0,0 +1,50 @@
+package mapper
+
+import (
+	"fmt"
+
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+)
+
+// AddPrefix is a path mapper which adds a prefix to the path
+// before forwarding the request to the downstream
+type AddPrefix struct {
+	prefix string
+}
+
+// addPrefixConfiguration is the configuration from which an AddPrefix is deserialised
+type addPrefixConfiguration struct {
+	Type   string `config:"type"`
+	Prefix string `config:"prefix"`
+}
+
+// NewAddPrefix creates a new AddPrefix which adds the given prefix
+func NewAddPrefix(prefix string) AddPrefix {
+	return AddPrefix{prefix: prefix}
+}
+
+// Map implements pathRewriter for AddPrefix
+func (m AddPrefix) Map(from string) (string, bool) {
+	return m.MapLogged(log.Nop(), from)
+}
+
+// MapLogged implements LoggingPathMapper for AddPrefix
+func (m AddPrefix) MapLogged(l log.Logger, from string) (string, bool) {
+	t := m.prefix + from
+	l.Debugf("Adding prefix %s: rewriting %s to %s", m.prefix, from, t)
+	return t, true
+}
+
+// From deserialises a configuration map (type == add-prefix, prefix) into an AddPrefix
+func (m *AddPrefix) From(c map[string]interface{}) error {
+	apc := addPrefixConfiguration{}
+	if err := decodeWithType(c, &apc, "add-prefix", "AddPrefix"); err != nil {
+		return err
+	}
+	if apc.Prefix == "" {
+		return fmt.Errorf(
+			"configuration %+v has no prefix field, required for AddPrefix", c)
+	}
+	m.prefix = apc.Prefix
+	return nil
+}
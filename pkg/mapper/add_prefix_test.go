@@ -0,0 +1,37 @@
+package mapper
+
+import "testing"
+
+func TestMapWithTypeAddPrefixAndPrefixKeyDeserialisesIntoAddPrefix(t *testing.T) {
+	m := map[string]interface{}{"type": "add-prefix", "prefix": "/tenant"}
+	err := (&AddPrefix{}).From(m)
+	if err != nil {
+		t.Errorf("Failed to deserialise %+v into AddPrefix: %s", m, err)
+	}
+}
+
+func TestMapWithNoPrefixKeyDoesNotDeserialiseIntoAddPrefix(t *testing.T) {
+	m := map[string]interface{}{"type": "add-prefix", "fixpre": "/tenant"}
+	err := (&AddPrefix{}).From(m)
+	if err == nil {
+		t.Errorf("Deserialised %+v into AddPrefix, but should not be possible", m)
+	}
+}
+
+func TestMapWithTypeNotAddPrefixDoesNotDeserialiseIntoAddPrefix(t *testing.T) {
+	m := map[string]interface{}{"type": "something-else", "prefix": "/tenant"}
+	err := (&AddPrefix{}).From(m)
+	if err == nil {
+		t.Errorf("Deserialised %+v into AddPrefix, but should not be possible", m)
+	}
+}
+
+func TestAddPrefixAddsPrefixToAllPaths(t *testing.T) {
+	m := NewAddPrefix("/tenant")
+	for from, expect := range map[string]string{"/": "/tenant/", "/users": "/tenant/users", "": "/tenant"} {
+		mapped, ok := m.Map(from)
+		if mapped != expect || !ok {
+			t.Errorf("AddPrefix mapped %s to %s (%t), expected %s (true)", from, mapped, ok, expect)
+		}
+	}
+}
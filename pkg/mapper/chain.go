@@ -0,0 +1,59 @@
+package mapper
+
+import (
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+)
+
+// Chain is a path mapper which applies a sequence of path mappers in order,
+// passing the output of each to the next. If any step cannot map the path, neither can the chain.
+type Chain struct {
+	steps []func(log.Logger, string) (string, bool)
+}
+
+// NewChain creates a new Chain which applies the given mapping functions in order
+func NewChain(steps ...func(string) (string, bool)) Chain {
+	c := Chain{}
+	for _, step := range steps {
+		c.steps = append(c.steps, unlogged(step))
+	}
+	return c
+}
+
+// NewChainOf creates a new Chain which applies the given path mappers in order,
+// which log how they map the path with the request's logger, if they can
+func NewChainOf(ms ...PathMapper) Chain {
+	c := Chain{}
+	for _, m := range ms {
+		if lm, ok := m.(LoggingPathMapper); ok {
+			c.steps = append(c.steps, lm.MapLogged)
+			continue
+		}
+		c.steps = append(c.steps, unlogged(m.Map))
+	}
+	return c
+}
+
+// unlogged adapts a mapping function, which does not log, to a chain step
+func unlogged(step func(string) (string, bool)) func(log.Logger, string) (string, bool) {
+	return func(_ log.Logger, from string) (string, bool) { return step(from) }
+}
+
+// Map implements pathRewriter for Chain
+func (m Chain) Map(from string) (string, bool) {
+	return m.MapLogged(log.Nop(), from)
+}
+
+// MapLogged maps the path like Map, logging the input & output of each step with the logger
+func (m Chain) MapLogged(l log.Logger, from string) (string, bool) {
+	current := from
+	for i, step := range m.steps {
+		next, ok := step(l, current)
+		l.Debugf("Path mapper step %d of %d: mapped %s to %s (ok: %t)",
+			i+1, len(m.steps), current, next, ok)
+		if !ok {
+			return "", false
+		}
+		current = next
+	}
+	return current, true
+}
@@ -0,0 +1,63 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestChainAppliesStepsInOrder(t *testing.T) {
+	for _, tc := range []struct {
+		chain    Chain
+		from     string
+		expect   string
+		expectOK bool
+	}{
+		{NewChain(), "/foo", "/foo", true},
+		{NewChain(NewRemovePrefix("/foo").Map), "/foo/bar", "/bar", true},
+		{NewChain(NewRemovePrefix("/foo").Map, NewAddPrefix("/baz").Map), "/foo/bar", "/baz/bar", true},
+		{NewChain(NewAddPrefix("/baz").Map, NewRemovePrefix("/foo").Map), "/foo/bar", "/baz/foo/bar", true},
+		{NewChain(NewReplacePrefix("/foo", "/oof").Map, NewReplacePrefix("/oof", "/rab").Map), "/foo/x", "/rab/x", true},
+	} {
+		mapped, ok := tc.chain.Map(tc.from)
+		if mapped != tc.expect || ok != tc.expectOK {
+			t.Errorf("Chain %#v mapped %s to %s (%t), expected %s (%t)",
+				tc.chain, tc.from, mapped, ok, tc.expect, tc.expectOK)
+		}
+	}
+}
+
+func TestChainDoesNotMapPathWhenAnyStepCannot(t *testing.T) {
+	strict := &ReplacePrefix{}
+	err := strict.From(map[string]interface{}{"type": "replace-prefix", "from": "/oof", "to": "/rab", "strict": "true"})
+	if err != nil {
+		t.Fatalf("Failed to deserialise ReplacePrefix: %s", err)
+	}
+	c := NewChain(NewRemovePrefix("/foo").Map, strict.Map, NewAddPrefix("/baz").Map)
+	mapped, ok := c.Map("/foo/bar")
+	if ok {
+		t.Errorf("Chain %#v mapped /foo/bar to %s, expected not to map", c, mapped)
+	}
+}
+
+func TestChainLogsMappingOfEachStepWithRequestLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	c := NewChainOf(&RemovePrefix{prefixes: []string{"/foo"}}, &AddPrefix{prefix: "/baz"})
+
+	mapped, ok := c.MapLogged(log.New(zap.New(core)), "/foo/bar")
+
+	if !ok || mapped != "/baz/bar" {
+		t.Fatalf("Mapped to %s (ok: %t), expected /baz/bar", mapped, ok)
+	}
+	for _, message := range []string{
+		"Removing prefix /foo: rewriting /foo/bar to /bar",
+		"Adding prefix /baz: rewriting /bar to /baz/bar",
+	} {
+		if logs.FilterMessage(message).Len() != 1 {
+			t.Errorf("Did not log '%s', logged %#v", message, logs.All())
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package mapper
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// Decode deserialises the path mapper configuration into the struct pointed to by into,
+// using the config tags on its fields, failing if the configuration contains any keys
+// not used by the struct or any values which cannot be converted to the field's type
+func Decode(c map[string]interface{}, into interface{}) error {
+	d, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		TagName:          "config",
+		ErrorUnused:      true,
+		WeaklyTypedInput: true,
+		Result:           into,
+	})
+	if err != nil {
+		return err
+	}
+	err = d.Decode(c)
+	var dErr *mapstructure.Error
+	if errors.As(err, &dErr) {
+		return fmt.Errorf("%s", strings.Join(dErr.Errors, ", "))
+	}
+	return err
+}
+
+// decodeWithType deserialises the configuration into the struct pointed to by into (see Decode),
+// additionally checking that its type is the one required by the named mapper
+func decodeWithType(c map[string]interface{}, into interface{}, t string, name string) error {
+	if err := Decode(c, into); err != nil {
+		return fmt.Errorf("configuration %+v is invalid for %s: %s", c, name, err)
+	}
+	ct, ok := c["type"]
+	if !ok {
+		return fmt.Errorf("configuration %+v has no type field, required for %s", c, name)
+	}
+	if ct != t {
+		return fmt.Errorf("configuration %+v has type %v, %s requires %s", c, ct, name, t)
+	}
+	return nil
+}
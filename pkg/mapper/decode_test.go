@@ -0,0 +1,49 @@
+package mapper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeReportsUnknownKeys(t *testing.T) {
+	into := struct {
+		Type string `config:"type"`
+	}{}
+	err := Decode(map[string]interface{}{"type": "foo", "prefxi": "/bar"}, &into)
+	if err == nil || !strings.Contains(err.Error(), "prefxi") {
+		t.Errorf("Expected unknown key prefxi to be reported, got error %v", err)
+	}
+}
+
+func TestDecodeReportsValuesOfWrongType(t *testing.T) {
+	into := struct {
+		Count  int  `config:"count"`
+		Strict bool `config:"strict"`
+	}{}
+	err := Decode(map[string]interface{}{"count": []interface{}{1, 2}, "strict": "maybe"}, &into)
+	if err == nil || !strings.Contains(err.Error(), "count") || !strings.Contains(err.Error(), "strict") {
+		t.Errorf("Expected invalid count and strict to be reported, got error %v", err)
+	}
+}
+
+func TestDecodeDeserialisesListsNumbersAndNestedOptions(t *testing.T) {
+	type nested struct {
+		Enabled bool `config:"enabled"`
+	}
+	into := struct {
+		Prefixes []string `config:"prefixes"`
+		Count    int      `config:"count"`
+		Nested   nested   `config:"nested"`
+	}{}
+	err := Decode(map[string]interface{}{
+		"prefixes": []interface{}{"/foo", "/bar"},
+		"count":    3,
+		"nested":   map[string]interface{}{"enabled": true},
+	}, &into)
+	if err != nil {
+		t.Fatalf("Failed to decode: %s", err)
+	}
+	if len(into.Prefixes) != 2 || into.Count != 3 || !into.Nested.Enabled {
+		t.Errorf("Decoded %+v, expected two prefixes, count 3 & nested enabled", into)
+	}
+}
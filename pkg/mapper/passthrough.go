@@ -1,34 +1,29 @@
 package mapper
 
 import (
-	"fmt"
-
 	"github.com/snasphysicist/ferp/v2/pkg/log"
 )
 
 // Passthrough is a path mapper which does not modify the path at all for the downstream
 type Passthrough struct{}
 
+// passthroughConfiguration is the configuration from which a Passthrough is deserialised
+type passthroughConfiguration struct {
+	Type string `config:"type"`
+}
+
 // Map implements pathRewriter for Passthrough
-func (Passthrough) Map(from string) string {
-	log.L().Infof("Rewriting %s to %s", from, from)
-	return from
+func (m Passthrough) Map(from string) (string, bool) {
+	return m.MapLogged(log.Nop(), from)
+}
+
+// MapLogged implements LoggingPathMapper for Passthrough
+func (Passthrough) MapLogged(l log.Logger, from string) (string, bool) {
+	l.Debugf("Rewriting %s to %s", from, from)
+	return from, true
 }
 
-// From deserialises a string-string map into a Passthrough
-func (m *Passthrough) From(c map[string]string) error {
-	if len(c) != 1 {
-		return fmt.Errorf(
-			"configuration %+v has %d fields, Passthrough requires exactly 1 (type == forward-unchanged)",
-			c, len(c))
-	}
-	t, tOK := c["type"]
-	if !tOK {
-		return fmt.Errorf(
-			"configuration %+v has no type field, required for Passthrough", c)
-	}
-	if t != "forward-unchanged" {
-		return fmt.Errorf("configuration %+v has type %s, Passthrough requires forward-unchanged", c, t)
-	}
-	return nil
+// From deserialises a configuration map (type == forward-unchanged) into a Passthrough
+func (m *Passthrough) From(c map[string]interface{}) error {
+	return decodeWithType(c, &passthroughConfiguration{}, "forward-unchanged", "Passthrough")
 }
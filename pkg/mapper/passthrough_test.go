@@ -3,7 +3,7 @@ package mapper
 import "testing"
 
 func TestMapWithTypeForwardUnchangedOnlyDeserialisesIntoPassthrough(t *testing.T) {
-	m := map[string]string{"type": "forward-unchanged"}
+	m := map[string]interface{}{"type": "forward-unchanged"}
 	err := (&Passthrough{}).From(m)
 	if err != nil {
 		t.Errorf("Failed to deserialise %+v into Passthrough: %s", m, err)
@@ -11,7 +11,7 @@ func TestMapWithTypeForwardUnchangedOnlyDeserialisesIntoPassthrough(t *testing.T
 }
 
 func TestMapWithFieldsOtherThanTypeDoesNotDeserialiseIntoPassthrough(t *testing.T) {
-	m := map[string]string{"type": "forward-unchanged", "foo": "bar"}
+	m := map[string]interface{}{"type": "forward-unchanged", "foo": "bar"}
 	err := (&Passthrough{}).From(m)
 	if err == nil {
 		t.Errorf("Deserialised %+v into Passthrough, but should not be possible", m)
@@ -19,7 +19,7 @@ func TestMapWithFieldsOtherThanTypeDoesNotDeserialiseIntoPassthrough(t *testing.
 }
 
 func TestMapWithTypeOtherThanForwardUnchangedDoesNotDeserialiseIntoPassthrough(t *testing.T) {
-	m := map[string]string{"type": "something-else"}
+	m := map[string]interface{}{"type": "something-else"}
 	err := (&Passthrough{}).From(m)
 	if err == nil {
 		t.Errorf("Deserialised %+v into Passthrough, but should not be possible", m)
@@ -0,0 +1,63 @@
+package mapper
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+)
+
+// Regex is a path mapper which replaces matches of a regular expression in the path
+// with a replacement, which may reference capture groups from the expression ($1, ${name})
+type Regex struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// regexConfiguration is the configuration from which a Regex is deserialised
+type regexConfiguration struct {
+	Type        string  `config:"type"`
+	Pattern     string  `config:"pattern"`
+	Replacement *string `config:"replacement"` // may be empty, so distinguish that from missing
+}
+
+// NewRegex creates a new Regex which replaces matches of the pattern with the replacement
+func NewRegex(pattern *regexp.Regexp, replacement string) Regex {
+	return Regex{pattern: pattern, replacement: replacement}
+}
+
+// Map implements pathRewriter for Regex
+func (m Regex) Map(from string) (string, bool) {
+	return m.MapLogged(log.Nop(), from)
+}
+
+// MapLogged implements LoggingPathMapper for Regex
+func (m Regex) MapLogged(l log.Logger, from string) (string, bool) {
+	t := m.pattern.ReplaceAllString(from, m.replacement)
+	l.Debugf("Replacing %s with %s: rewriting %s to %s", m.pattern, m.replacement, from, t)
+	return t, true
+}
+
+// From deserialises a configuration map (type == regex, pattern, replacement)
+// into a Regex, compiling the pattern
+func (m *Regex) From(c map[string]interface{}) error {
+	rc := regexConfiguration{}
+	if err := decodeWithType(c, &rc, "regex", "Regex"); err != nil {
+		return err
+	}
+	if rc.Pattern == "" {
+		return fmt.Errorf(
+			"configuration %+v has no pattern field, required for Regex", c)
+	}
+	if rc.Replacement == nil {
+		return fmt.Errorf(
+			"configuration %+v has no replacement field, required for Regex", c)
+	}
+	compiled, err := regexp.Compile(rc.Pattern)
+	if err != nil {
+		return fmt.Errorf("configuration %+v has invalid pattern for Regex: %s", c, err)
+	}
+	m.pattern = compiled
+	m.replacement = *rc.Replacement
+	return nil
+}
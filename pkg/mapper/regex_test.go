@@ -0,0 +1,61 @@
+package mapper
+
+import "testing"
+
+func TestMapWithTypeRegexPatternAndReplacementKeysDeserialisesIntoRegex(t *testing.T) {
+	m := map[string]interface{}{"type": "regex", "pattern": "^/foo/(.*)$", "replacement": "/bar/$1"}
+	err := (&Regex{}).From(m)
+	if err != nil {
+		t.Errorf("Failed to deserialise %+v into Regex: %s", m, err)
+	}
+}
+
+func TestMapWithInvalidPatternDoesNotDeserialiseIntoRegex(t *testing.T) {
+	m := map[string]interface{}{"type": "regex", "pattern": "^/foo/(.*$", "replacement": "/bar/$1"}
+	err := (&Regex{}).From(m)
+	if err == nil {
+		t.Errorf("Deserialised %+v into Regex, but should not be possible", m)
+	}
+}
+
+func TestMapWithNoReplacementKeyDoesNotDeserialiseIntoRegex(t *testing.T) {
+	m := map[string]interface{}{"type": "regex", "pattern": "^/foo/(.*)$", "placement": "/bar/$1"}
+	err := (&Regex{}).From(m)
+	if err == nil {
+		t.Errorf("Deserialised %+v into Regex, but should not be possible", m)
+	}
+}
+
+func TestMapWithTypeNotRegexDoesNotDeserialiseIntoRegex(t *testing.T) {
+	m := map[string]interface{}{"type": "something-else", "pattern": "^/foo/(.*)$", "replacement": "/bar/$1"}
+	err := (&Regex{}).From(m)
+	if err == nil {
+		t.Errorf("Deserialised %+v into Regex, but should not be possible", m)
+	}
+}
+
+func TestRegexReplacesMatchesUsingCaptureGroups(t *testing.T) {
+	for _, tc := range []struct {
+		pattern     string
+		replacement string
+		from        string
+		expect      string
+	}{
+		{`^/users/([0-9]+)/avatar$`, "/avatars/$1.png", "/users/123/avatar", "/avatars/123.png"},
+		{`^/users/(?P<id>[0-9]+)/avatar$`, "/avatars/${id}.png", "/users/123/avatar", "/avatars/123.png"},
+		{`^/users/([0-9]+)/avatar$`, "/avatars/$1.png", "/users/abc/avatar", "/users/abc/avatar"},
+		{`\.html$`, "", "/static/index.html", "/static/index"},
+	} {
+		m := &Regex{}
+		err := m.From(map[string]interface{}{"type": "regex", "pattern": tc.pattern, "replacement": tc.replacement})
+		if err != nil {
+			t.Errorf("Failed to deserialise Regex with pattern %s: %s", tc.pattern, err)
+			continue
+		}
+		mapped, _ := m.Map(tc.from)
+		if mapped != tc.expect {
+			t.Errorf("Regex %s -> %s mapped %s to %s, expected %s",
+				tc.pattern, tc.replacement, tc.from, mapped, tc.expect)
+		}
+	}
+}
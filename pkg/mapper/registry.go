@@ -0,0 +1,79 @@
+package mapper
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+)
+
+// PathMapper is an object which can rewrite paths from the incoming request,
+// to the downstream request, and can deserialise itself from its configuration (see Decode).
+// Map returns false if the path cannot be mapped, and so should not be forwarded.
+type PathMapper interface {
+	Map(string) (string, bool)
+	From(map[string]interface{}) error
+}
+
+// LoggingPathMapper is a path mapper which can log how it maps each path, with the logger
+// of the request the path is from. Only the input & output are logged for other path mappers.
+type LoggingPathMapper interface {
+	MapLogged(log.Logger, string) (string, bool)
+}
+
+// Factory creates a new, not yet deserialised, path mapper
+type Factory func() PathMapper
+
+// Registry holds the factories for all known path mappers, keyed by the type
+// which selects them in configuration
+type Registry struct {
+	lock      sync.RWMutex
+	factories map[string]Factory
+}
+
+// NewRegistry creates a registry containing only the built in path mappers
+func NewRegistry() *Registry {
+	return &Registry{factories: map[string]Factory{
+		"forward-unchanged": func() PathMapper { return &Passthrough{} },
+		"remove-prefix":     func() PathMapper { return &RemovePrefix{} },
+		"replace-prefix":    func() PathMapper { return &ReplacePrefix{} },
+		"add-prefix":        func() PathMapper { return &AddPrefix{} },
+		"regex":             func() PathMapper { return &Regex{} },
+	}}
+}
+
+// Register makes a custom path mapper available for configuration with the given type.
+// It must be called before the configuration is loaded, and fails if the type is already taken.
+func (r *Registry) Register(t string, f Factory) error {
+	if t == "" || f == nil {
+		return fmt.Errorf("cannot register path mapper with type '%s' and factory %p", t, f)
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.factories[t]; ok {
+		return fmt.Errorf("a path mapper with type '%s' is already registered", t)
+	}
+	r.factories[t] = f
+	return nil
+}
+
+// Lookup finds the factory for path mappers of the given type, if one is registered
+func (r *Registry) Lookup(t string) (Factory, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	f, ok := r.factories[t]
+	return f, ok
+}
+
+// Types lists, alphabetically, the types of all registered path mappers
+func (r *Registry) Types() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	ts := make([]string, 0)
+	for t := range r.factories {
+		ts = append(ts, t)
+	}
+	sort.Strings(ts)
+	return ts
+}
@@ -0,0 +1,53 @@
+package mapper
+
+import (
+	"testing"
+)
+
+func TestRegisteredPathMapperCanBeLookedUpByType(t *testing.T) {
+	r := NewRegistry()
+	err := r.Register("test-registered", func() PathMapper { return &AddPrefix{} })
+	if err != nil {
+		t.Fatalf("Failed to register path mapper: %s", err)
+	}
+	f, ok := r.Lookup("test-registered")
+	if !ok {
+		t.Fatalf("Registered path mapper was not found")
+	}
+	if _, ok := f().(*AddPrefix); !ok {
+		t.Errorf("Registered factory created %#v, expected an AddPrefix", f())
+	}
+}
+
+func TestRegistrationsAreOnlyInTheirRegistry(t *testing.T) {
+	err := NewRegistry().Register("test-registered", func() PathMapper { return &AddPrefix{} })
+	if err != nil {
+		t.Fatalf("Failed to register path mapper: %s", err)
+	}
+	if _, ok := NewRegistry().Lookup("test-registered"); ok {
+		t.Errorf("Path mapper registered in one registry was found in another")
+	}
+}
+
+func TestPathMapperCannotBeRegisteredWithTakenType(t *testing.T) {
+	err := NewRegistry().Register("remove-prefix", func() PathMapper { return &AddPrefix{} })
+	if err == nil {
+		t.Errorf("Registered path mapper with built in type remove-prefix, but should not be possible")
+	}
+}
+
+func TestPathMapperCannotBeRegisteredWithoutFactory(t *testing.T) {
+	err := NewRegistry().Register("test-no-factory", nil)
+	if err == nil {
+		t.Errorf("Registered path mapper with no factory, but should not be possible")
+	}
+}
+
+func TestAllBuiltInPathMappersAreRegistered(t *testing.T) {
+	r := NewRegistry()
+	for _, bt := range []string{"forward-unchanged", "remove-prefix", "replace-prefix", "add-prefix", "regex"} {
+		if _, ok := r.Lookup(bt); !ok {
+			t.Errorf("Built in path mapper %s is not registered", bt)
+		}
+	}
+}
@@ -2,48 +2,64 @@ package mapper
 
 import (
 	"fmt"
-	"log"
 	"strings"
+
+	"github.com/snasphysicist/ferp/v2/pkg/functional"
+	"github.com/snasphysicist/ferp/v2/pkg/log"
 )
 
 // RemovePrefix is a path mapper which removes a prefix from the path
 // before forwarding the request to the downstream
 type RemovePrefix struct {
-	prefix string
+	prefixes []string
+	strict   bool
+}
+
+// removePrefixConfiguration is the configuration from which a RemovePrefix is deserialised
+type removePrefixConfiguration struct {
+	Type   string   `config:"type"`
+	Prefix []string `config:"prefix"` // a single prefix is decoded as a list of one
+	Strict bool     `config:"strict"`
 }
 
-// NewRemovePrefix creates a new RemovePrefix which removes the given prefix
-func NewRemovePrefix(prefix string) RemovePrefix {
-	return RemovePrefix{prefix: prefix}
+// NewRemovePrefix creates a new RemovePrefix which removes the first of the given prefixes the path has
+func NewRemovePrefix(prefixes ...string) RemovePrefix {
+	return RemovePrefix{prefixes: prefixes}
 }
 
 // Map implements pathRewriter for RemovePrefix
-func (m RemovePrefix) Map(from string) string {
-	t := strings.TrimPrefix(from, m.prefix)
-	log.Printf("Removing prefix %s: rewriting %s to %s", m.prefix, from, t)
-	return t
+func (m RemovePrefix) Map(from string) (string, bool) {
+	return m.MapLogged(log.Nop(), from)
 }
 
-// From deserialises a string-string map into a RemovePrefix
-func (m *RemovePrefix) From(c map[string]string) error {
-	if len(c) != 2 {
-		return fmt.Errorf(
-			"configuration %+v has %d fields, RemovePrefix requires exactly 2 (type == remove-prefix, prefix)",
-			c, len(c))
+// MapLogged implements LoggingPathMapper for RemovePrefix
+func (m RemovePrefix) MapLogged(l log.Logger, from string) (string, bool) {
+	matching := functional.Filter(m.prefixes, func(p string) bool { return strings.HasPrefix(from, p) })
+	if len(matching) == 0 && m.strict {
+		l.Debugf("Path %s does not have any prefix %v, not mapping", from, m.prefixes)
+		return "", false
 	}
-	t, tOK := c["type"]
-	if !tOK {
-		return fmt.Errorf(
-			"configuration %+v has no type field, required for RemovePrefix", c)
+	if len(matching) == 0 {
+		l.Debugf("Path %s does not have any prefix %v, rewriting %s to %s", from, m.prefixes, from, from)
+		return from, true
 	}
-	if t != "remove-prefix" {
-		return fmt.Errorf("configuration %+v has type %s, RemovePrefix requires remove-prefix", c, t)
+	t := strings.TrimPrefix(from, matching[0])
+	l.Debugf("Removing prefix %s: rewriting %s to %s", matching[0], from, t)
+	return t, true
+}
+
+// From deserialises a configuration map (type == remove-prefix, prefix or list of prefixes,
+// optionally strict) into a RemovePrefix
+func (m *RemovePrefix) From(c map[string]interface{}) error {
+	rpc := removePrefixConfiguration{}
+	if err := decodeWithType(c, &rpc, "remove-prefix", "RemovePrefix"); err != nil {
+		return err
 	}
-	p, pOK := c["prefix"]
-	if !pOK {
+	if len(rpc.Prefix) == 0 {
 		return fmt.Errorf(
 			"configuration %+v has no prefix field, required for RemovePrefix", c)
 	}
-	m.prefix = p
+	m.prefixes = rpc.Prefix
+	m.strict = rpc.Strict
 	return nil
 }
@@ -3,7 +3,7 @@ package mapper
 import "testing"
 
 func TestMapWithTypeRemovePrefixAndPrefixKeyDeserialisesIntoRemovePrefix(t *testing.T) {
-	m := map[string]string{"type": "remove-prefix", "prefix": "foo"}
+	m := map[string]interface{}{"type": "remove-prefix", "prefix": "foo"}
 	err := (&RemovePrefix{}).From(m)
 	if err != nil {
 		t.Errorf("Failed to deserialise %+v into RemovePrefix: %s", m, err)
@@ -11,7 +11,7 @@ func TestMapWithTypeRemovePrefixAndPrefixKeyDeserialisesIntoRemovePrefix(t *test
 }
 
 func TestMapWithNoPrefixKeyDoesNotDeserialiseIntoRemovePrefix(t *testing.T) {
-	m := map[string]string{"type": "remove-prefix", "fixpre": "foo"}
+	m := map[string]interface{}{"type": "remove-prefix", "fixpre": "foo"}
 	err := (&RemovePrefix{}).From(m)
 	if err == nil {
 		t.Errorf("Deserialised %+v into RemovePrefix, but should not be possible", m)
@@ -19,9 +19,66 @@ func TestMapWithNoPrefixKeyDoesNotDeserialiseIntoRemovePrefix(t *testing.T) {
 }
 
 func TestMapWithTypeNotRemovePrefixDoesNotDeserialiseIntoRemovePrefix(t *testing.T) {
-	m := map[string]string{"type": "something-else", "prefix": "foo"}
+	m := map[string]interface{}{"type": "something-else", "prefix": "foo"}
 	err := (&RemovePrefix{}).From(m)
 	if err == nil {
 		t.Errorf("Deserialised %+v into RemovePrefix, but should not be possible", m)
 	}
 }
+
+func TestMapWithInvalidStrictDoesNotDeserialiseIntoRemovePrefix(t *testing.T) {
+	m := map[string]interface{}{"type": "remove-prefix", "prefix": "foo", "strict": "maybe"}
+	err := (&RemovePrefix{}).From(m)
+	if err == nil {
+		t.Errorf("Deserialised %+v into RemovePrefix, but should not be possible", m)
+	}
+}
+
+func TestRemovePrefixMapsPathsWithoutPrefixUnchangedUnlessStrict(t *testing.T) {
+	for _, tc := range []struct {
+		strict   string
+		from     string
+		expect   string
+		expectOK bool
+	}{
+		{"false", "/foo/bar", "/bar", true},
+		{"false", "/baz/bar", "/baz/bar", true},
+		{"true", "/foo/bar", "/bar", true},
+		{"true", "/baz/bar", "", false},
+	} {
+		m := &RemovePrefix{}
+		err := m.From(map[string]interface{}{"type": "remove-prefix", "prefix": "/foo", "strict": tc.strict})
+		if err != nil {
+			t.Errorf("Failed to deserialise RemovePrefix with strict %s: %s", tc.strict, err)
+			continue
+		}
+		mapped, ok := m.Map(tc.from)
+		if mapped != tc.expect || ok != tc.expectOK {
+			t.Errorf("RemovePrefix with strict %s mapped %s to %s (%t), expected %s (%t)",
+				tc.strict, tc.from, mapped, ok, tc.expect, tc.expectOK)
+		}
+	}
+}
+
+func TestMapWithListOfPrefixesDeserialisesIntoRemovePrefix(t *testing.T) {
+	m := map[string]interface{}{"type": "remove-prefix", "prefix": []interface{}{"/foo", "/bar"}}
+	err := (&RemovePrefix{}).From(m)
+	if err != nil {
+		t.Errorf("Failed to deserialise %+v into RemovePrefix: %s", m, err)
+	}
+}
+
+func TestRemovePrefixRemovesFirstMatchingPrefixFromList(t *testing.T) {
+	m := NewRemovePrefix("/foo/bar", "/foo", "/baz")
+	for from, expect := range map[string]string{
+		"/foo/bar/x": "/x",
+		"/foo/x":     "/x",
+		"/baz/x":     "/x",
+		"/oof/x":     "/oof/x",
+	} {
+		mapped, ok := m.Map(from)
+		if mapped != expect || !ok {
+			t.Errorf("RemovePrefix mapped %s to %s (%t), expected %s (true)", from, mapped, ok, expect)
+		}
+	}
+}
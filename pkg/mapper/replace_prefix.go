@@ -0,0 +1,70 @@
+package mapper
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+)
+
+// ReplacePrefix is a path mapper which replaces one prefix
+// of the path with another before forwarding the request to the downstream
+type ReplacePrefix struct {
+	from   string
+	to     string
+	strict bool
+}
+
+// replacePrefixConfiguration is the configuration from which a ReplacePrefix is deserialised
+type replacePrefixConfiguration struct {
+	Type   string  `config:"type"`
+	From   string  `config:"from"`
+	To     *string `config:"to"` // may be empty, so distinguish that from missing
+	Strict bool    `config:"strict"`
+}
+
+// NewReplacePrefix creates a new ReplacePrefix which replaces the prefix from with to
+func NewReplacePrefix(from string, to string) ReplacePrefix {
+	return ReplacePrefix{from: from, to: to}
+}
+
+// Map implements pathRewriter for ReplacePrefix
+func (m ReplacePrefix) Map(from string) (string, bool) {
+	return m.MapLogged(log.Nop(), from)
+}
+
+// MapLogged implements LoggingPathMapper for ReplacePrefix
+func (m ReplacePrefix) MapLogged(l log.Logger, from string) (string, bool) {
+	if !strings.HasPrefix(from, m.from) && m.strict {
+		l.Debugf("Path %s does not have prefix %s, not mapping", from, m.from)
+		return "", false
+	}
+	if !strings.HasPrefix(from, m.from) {
+		l.Debugf("Path %s does not have prefix %s, rewriting %s to %s", from, m.from, from, from)
+		return from, true
+	}
+	t := m.to + strings.TrimPrefix(from, m.from)
+	l.Debugf("Replacing prefix %s with %s: rewriting %s to %s", m.from, m.to, from, t)
+	return t, true
+}
+
+// From deserialises a configuration map (type == replace-prefix, from, to, optionally strict)
+// into a ReplacePrefix
+func (m *ReplacePrefix) From(c map[string]interface{}) error {
+	rpc := replacePrefixConfiguration{}
+	if err := decodeWithType(c, &rpc, "replace-prefix", "ReplacePrefix"); err != nil {
+		return err
+	}
+	if rpc.From == "" {
+		return fmt.Errorf(
+			"configuration %+v has no from field, required for ReplacePrefix", c)
+	}
+	if rpc.To == nil {
+		return fmt.Errorf(
+			"configuration %+v has no to field, required for ReplacePrefix", c)
+	}
+	m.from = rpc.From
+	m.to = *rpc.To
+	m.strict = rpc.Strict
+	return nil
+}
@@ -0,0 +1,61 @@
+package mapper
+
+import "testing"
+
+func TestMapWithTypeReplacePrefixAndFromAndToKeysDeserialisesIntoReplacePrefix(t *testing.T) {
+	m := map[string]interface{}{"type": "replace-prefix", "from": "/v1/", "to": "/api/v2/"}
+	err := (&ReplacePrefix{}).From(m)
+	if err != nil {
+		t.Errorf("Failed to deserialise %+v into ReplacePrefix: %s", m, err)
+	}
+}
+
+func TestMapWithStrictKeyDeserialisesIntoReplacePrefix(t *testing.T) {
+	m := map[string]interface{}{"type": "replace-prefix", "from": "/v1/", "to": "/api/v2/", "strict": "true"}
+	err := (&ReplacePrefix{}).From(m)
+	if err != nil {
+		t.Errorf("Failed to deserialise %+v into ReplacePrefix: %s", m, err)
+	}
+}
+
+func TestMapWithNoToKeyDoesNotDeserialiseIntoReplacePrefix(t *testing.T) {
+	m := map[string]interface{}{"type": "replace-prefix", "from": "/v1/", "ot": "/api/v2/"}
+	err := (&ReplacePrefix{}).From(m)
+	if err == nil {
+		t.Errorf("Deserialised %+v into ReplacePrefix, but should not be possible", m)
+	}
+}
+
+func TestMapWithTypeNotReplacePrefixDoesNotDeserialiseIntoReplacePrefix(t *testing.T) {
+	m := map[string]interface{}{"type": "something-else", "from": "/v1/", "to": "/api/v2/"}
+	err := (&ReplacePrefix{}).From(m)
+	if err == nil {
+		t.Errorf("Deserialised %+v into ReplacePrefix, but should not be possible", m)
+	}
+}
+
+func TestReplacePrefixReplacesPrefixAndHandlesOtherPathsAccordingToStrict(t *testing.T) {
+	for _, tc := range []struct {
+		strict   string
+		from     string
+		expect   string
+		expectOK bool
+	}{
+		{"false", "/v1/users", "/api/v2/users", true},
+		{"false", "/v0/users", "/v0/users", true},
+		{"true", "/v1/users", "/api/v2/users", true},
+		{"true", "/v0/users", "", false},
+	} {
+		m := &ReplacePrefix{}
+		err := m.From(map[string]interface{}{"type": "replace-prefix", "from": "/v1/", "to": "/api/v2/", "strict": tc.strict})
+		if err != nil {
+			t.Errorf("Failed to deserialise ReplacePrefix with strict %s: %s", tc.strict, err)
+			continue
+		}
+		mapped, ok := m.Map(tc.from)
+		if mapped != tc.expect || ok != tc.expectOK {
+			t.Errorf("ReplacePrefix with strict %s mapped %s to %s (%t), expected %s (%t)",
+				tc.strict, tc.from, mapped, ok, tc.expect, tc.expectOK)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/snasphysicist/ferp/v2/pkg/access"
+	"github.com/snasphysicist/ferp/v2/pkg/request"
+)
+
+// AccessLog writes an entry to the access log for each request, from its metrics,
+// so must come after the logging middleware
+func AccessLog(al *access.Log) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if al == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+			route := ""
+			if rc := chi.RouteContext(r.Context()); rc != nil {
+				route = rc.RoutePattern()
+			}
+			al.Write(access.NewEntry(r, route, request.MetricsFrom(r.Context())))
+		})
+	}
+}
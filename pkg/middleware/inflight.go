@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// CountInFlight is a middleware which keeps count of the requests currently being handled
+func CountInFlight(count *atomic.Int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			count.Add(1)
+			defer count.Add(-1)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
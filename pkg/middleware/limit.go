@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+)
+
+// LimitHeaders is a middleware which rejects requests with more than
+// the maximum number of header values with a 431, or does nothing if the maximum is 0
+func LimitHeaders(max int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if max == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			count := 0
+			for _, vs := range r.Header {
+				count += len(vs)
+			}
+			if count > max {
+				log.From(r.Context()).Infof("Request has %d headers, more than the maximum %d", count, max)
+				w.WriteHeader(http.StatusRequestHeaderFieldsTooLarge)
+				_, err := w.Write([]byte(tooManyHeadersMessage))
+				if err != nil {
+					log.From(r.Context()).Errorf("Failed to write error response body: %s", err)
+				}
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// tooManyHeadersMessage is sent when a request has more headers than allowed
+const tooManyHeadersMessage = "431: too many headers"
@@ -1,63 +1,97 @@
 package middleware
 
 import (
+	"io"
 	"net/http"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/snasphysicist/ferp/v2/pkg/log"
+	"github.com/snasphysicist/ferp/v2/pkg/request"
 )
 
-// RouterWithDefaults returns a new router with the default
-// middlewares for the proxy servers attached
-func RouterWithDefaults() *chi.Mux {
+// RouterWithDefaults returns a new router with the default middlewares for the proxy servers
+// attached, identifying requests by the request ID header and logging with the logger
+func RouterWithDefaults(l log.Logger, requestIDHeader string) *chi.Mux {
 	r := chi.NewRouter()
-	r.Use(logging)
+	r.Use(RequestID(requestIDHeader))
+	r.Use(logging(l))
 	return r
 }
 
-// logging is a logging middleware which uses the logger from this service
-func logging(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		logRequest(w, r, next)
-	})
+// logging is a logging middleware which derives a logger for each request from the logger,
+// and measures each request, making the logger & metrics available to later
+// middleware & handlers through the request context
+func logging(l log.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logRequest(w, r, next, requestLogger(l, r))
+		})
+	}
 }
 
-// logRequest logs some information about the request (method, url, timing, ...)
-func logRequest(w http.ResponseWriter, r *http.Request, next http.Handler) {
-	start := time.Now()
-	log.L().Infof("%s request to %s", r.Method, r.URL.String())
-	rr := wrap(w)
-	next.ServeHTTP(&rr, r)
-	log.L().Infof("Responded %d, wrote %d, took %s", rr.code, rr.written, time.Since(start))
+// requestLogger derives the logger for the request, with its method, path & ID, if it has one
+func requestLogger(l log.Logger, r *http.Request) log.Logger {
+	l = l.With("method", r.Method, "path", r.URL.Path)
+	if id, ok := request.IDFrom(r.Context()); ok {
+		l = l.With("request-id", id.Value)
+	}
+	return l
 }
 
-// responseRecorder records some properties of the response which we want to log
-type responseRecorder struct {
-	code    int
-	written int
-	w       http.ResponseWriter
+// logRequest logs some information about the request (method, url, timing, ...) with its logger
+func logRequest(w http.ResponseWriter, r *http.Request, next http.Handler, l log.Logger) {
+	m := request.NewMetrics()
+	l.Infof("%s request to %s", r.Method, r.URL.String())
+	r = r.WithContext(log.WithLogger(request.WithMetrics(r.Context(), m), l))
+	if r.Body != nil && r.Body != http.NoBody {
+		r.Body = countingBody{ReadCloser: r.Body, m: m}
+	}
+	next.ServeHTTP(&responseRecorder{w: w, m: m}, r)
+	l.Infof("Responded %d, read %d, wrote %d, first byte after %s, took %s",
+		m.Status(), m.BytesRead(), m.BytesWritten(), m.FirstByte(), time.Since(m.Start))
 }
 
-// wrap wraps the provided writer in a recorder
-func wrap(w http.ResponseWriter) responseRecorder {
-	return responseRecorder{w: w}
+// responseRecorder records the properties of the response which we want to log in the metrics
+type responseRecorder struct {
+	w http.ResponseWriter
+	m *request.Metrics
 }
 
-// WriteHeader captures the status code and forwards it to the wrapper writer
+// WriteHeader records the status code and forwards it to the wrapped writer
 func (w *responseRecorder) WriteHeader(status int) {
-	w.code = status
+	w.m.WroteHeader(status)
 	w.w.WriteHeader(status)
 }
 
-// Write writes the bytes to the wrapper writer and records the number of bytes written
+// Write writes the bytes to the wrapped writer and records the number of bytes written,
+// and the implicit 200 status if the headers were not written first
 func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.m.WroteHeader(http.StatusOK)
 	n, err := w.w.Write(b)
-	w.written = n
+	w.m.Wrote(n)
 	return n, err
 }
 
+// Unwrap returns the wrapped writer, so that http.ResponseController can reach it
+func (w *responseRecorder) Unwrap() http.ResponseWriter {
+	return w.w
+}
+
 // Header just forwards to the wrapped writer's Header method
 func (w *responseRecorder) Header() http.Header {
 	return w.w.Header()
 }
+
+// countingBody records the number of bytes read from a request body in the metrics
+type countingBody struct {
+	io.ReadCloser
+	m *request.Metrics
+}
+
+// Read reads from the wrapped body, recording the number of bytes read
+func (b countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.m.Read(n)
+	return n, err
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+	"github.com/snasphysicist/ferp/v2/pkg/request"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLoggingMeasuresWholeResponseWithImplicitStatus(t *testing.T) {
+	var m *request.Metrics
+	h := logging(log.Nop())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m = request.MetricsFrom(r.Context())
+		if _, err := io.Copy(io.Discard, r.Body); err != nil {
+			t.Errorf("Failed to read request body: %s", err)
+		}
+		for _, chunk := range []string{"first ", "second ", "third"} {
+			if _, err := w.Write([]byte(chunk)); err != nil {
+				t.Errorf("Failed to write response chunk: %s", err)
+			}
+		}
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader("request body")))
+
+	if m.Status() != http.StatusOK {
+		t.Errorf("Recorded status %d, expected implicit %d", m.Status(), http.StatusOK)
+	}
+	if m.BytesWritten() != int64(len("first second third")) {
+		t.Errorf("Recorded %d bytes written, expected all %d", m.BytesWritten(), len("first second third"))
+	}
+	if m.BytesRead() != int64(len("request body")) {
+		t.Errorf("Recorded %d bytes read, expected %d", m.BytesRead(), len("request body"))
+	}
+	if m.FirstByte() <= 0 {
+		t.Errorf("Recorded time to first byte %s, expected it to be positive", m.FirstByte())
+	}
+}
+
+func TestLoggingRecordsFirstStatusWritten(t *testing.T) {
+	var m *request.Metrics
+	h := logging(log.Nop())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m = request.MetricsFrom(r.Context())
+		w.WriteHeader(http.StatusNotFound)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	if m.Status() != http.StatusNotFound {
+		t.Errorf("Recorded status %d, expected the first written %d", m.Status(), http.StatusNotFound)
+	}
+}
+
+func TestRequestLoggerCarriesRequestFields(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	h := RequestID("X-Request-ID")(logging(log.New(zap.New(core)))(Route("/users/{id}")(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			log.From(r.Context()).Infof("handled")
+		}))))
+	r := httptest.NewRequest(http.MethodGet, "/users/1", http.NoBody)
+	r.Header.Set("X-Request-ID", "abc-123")
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	handled := logs.FilterMessage("handled").All()
+	if len(handled) != 1 {
+		t.Fatalf("Logged %#v, expected one entry from the handler", logs.All())
+	}
+	fields := handled[0].ContextMap()
+	for k, v := range map[string]string{
+		"method":     http.MethodGet,
+		"path":       "/users/1",
+		"route":      "/users/{id}",
+		"request-id": "abc-123",
+	} {
+		if fields[k] != v {
+			t.Errorf("Handler logged with %s '%v', expected '%s'", k, fields[k], v)
+		}
+	}
+	if id := w.Header().Get("X-Request-ID"); id != "abc-123" {
+		t.Errorf("Echoed request ID '%s', expected 'abc-123'", id)
+	}
+}
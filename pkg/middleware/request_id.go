@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/snasphysicist/ferp/v2/pkg/request"
+)
+
+// RequestID identifies each request by the ID in the header, or a new one if it has none
+// or it is invalid, and echoes the ID on the response. The ID is available to later
+// middleware & handlers through the request context, so this must come before logging.
+func RequestID(header string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := request.IDFor(r, header)
+			w.Header().Set(header, id.Value)
+			next.ServeHTTP(w, r.WithContext(request.WithID(r.Context(), id)))
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+)
+
+// Route adds the route pattern which matched the request to its logger
+func Route(pattern string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			l := log.From(r.Context()).With("route", pattern)
+			next.ServeHTTP(w, r.WithContext(log.WithLogger(r.Context(), l)))
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+)
+
+// Deadlines is a middleware which replaces the server's read and write deadlines for
+// the request with ones the given durations from now, leaving either unchanged if 0
+func Deadlines(read time.Duration, write time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if read == 0 && write == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rc := http.NewResponseController(w)
+			if read != 0 {
+				if err := rc.SetReadDeadline(time.Now().Add(read)); err != nil {
+					log.From(r.Context()).Errorf("Failed to set read deadline of %s: %s", read, err)
+				}
+			}
+			if write != 0 {
+				if err := rc.SetWriteDeadline(time.Now().Add(write)); err != nil {
+					log.From(r.Context()).Errorf("Failed to set write deadline of %s: %s", write, err)
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
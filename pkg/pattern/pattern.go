@@ -0,0 +1,143 @@
+package pattern
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/snasphysicist/ferp/v2/pkg/functional"
+)
+
+// Captures lists the names of the URL parameters captured by the given chi route pattern,
+// i.e. the name of each {name} or {name:regexp} segment, plus * if it ends in a wildcard
+func Captures(route string) []string {
+	cs := functional.Map(parameters(route), func(p parameter) string { return p.name })
+	if strings.HasSuffix(route, "*") {
+		cs = append(cs, "*")
+	}
+	return cs
+}
+
+// Group is a named group of the regexp of a {name:regexp} parameter,
+// which captures part of the value of the parameter
+type Group struct {
+	Parameter string
+	re        *regexp.Regexp
+	index     int
+}
+
+// Value finds the value captured by the group in the value of its parameter
+func (g Group) Value(parameter string) string {
+	m := g.re.FindStringSubmatch(parameter)
+	if m == nil {
+		return ""
+	}
+	return m[g.index]
+}
+
+// Groups finds the named groups of the regexps of the {name:regexp} parameters of the
+// given chi route pattern, by name, e.g. year & month in {date:(?P<year>[0-9]{4})-(?P<month>[0-9]{2})},
+// returning an error if any regexp is invalid or any name is captured more than once
+func Groups(route string) (map[string]Group, error) {
+	gs := make(map[string]Group)
+	ps := parameters(route)
+	names := functional.Map(ps, func(p parameter) string { return p.name })
+	for _, p := range ps {
+		if p.regexp == "" {
+			continue
+		}
+		re, err := regexp.Compile("^(?:" + p.regexp + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("parameter {%s} has invalid regexp: %s", p.name, err)
+		}
+		for i, n := range re.SubexpNames() {
+			if n == "" {
+				continue
+			}
+			if _, ok := gs[n]; ok || functional.Contains(names, n) {
+				return nil, fmt.Errorf("regexp group %s of parameter {%s} is captured more than once", n, p.name)
+			}
+			gs[n] = Group{Parameter: p.name, re: re, index: i}
+		}
+	}
+	return gs, nil
+}
+
+// parameter is a {name} or {name:regexp} segment of a chi route pattern
+type parameter struct {
+	name   string
+	regexp string
+}
+
+// parameters finds all the {name} or {name:regexp} segments of the chi route pattern, in order
+func parameters(route string) []parameter {
+	ps := make([]parameter, 0)
+	depth := 0
+	start := 0
+	for i, c := range route {
+		switch c {
+		case '{':
+			if depth == 0 {
+				start = i + 1
+			}
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				parts := strings.SplitN(route[start:i], ":", 2)
+				p := parameter{name: parts[0]}
+				if len(parts) == 2 {
+					p.regexp = parts[1]
+				}
+				ps = append(ps, p)
+			}
+		}
+	}
+	return ps
+}
+
+// Placeholders lists the names of all {name} placeholders in the template, in order of appearance
+func Placeholders(template string) []string {
+	return functional.Map(placeholder.FindAllStringSubmatch(template, -1),
+		func(m []string) string { return m[1] })
+}
+
+// Expand replaces each {name} placeholder in the template with the value returned
+// by calling value with name, path escaped, or query escaped if after the ? in the template
+func Expand(template string, value func(string) string) string {
+	path, query, hasQuery := strings.Cut(template, "?")
+	expanded := expand(path, value, escapeSegments)
+	if !hasQuery {
+		return expanded
+	}
+	return expanded + "?" + expand(query, value, escapeQuery)
+}
+
+// expand replaces each {name} placeholder in the template with the value
+// returned by calling value with name, escaped with escape
+func expand(template string, value func(string) string, escape func(string) string) string {
+	return placeholder.ReplaceAllStringFunc(template, func(p string) string {
+		return escape(value(placeholder.FindStringSubmatch(p)[1]))
+	})
+}
+
+// placeholder matches a {name} placeholder in a template, capturing the name
+var placeholder = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// escapeSegments path escapes each slash separated segment of the value,
+// so that values captured by wildcards retain their slashes
+func escapeSegments(v string) string {
+	if unescaped, err := url.PathUnescape(v); err == nil {
+		v = unescaped
+	}
+	return strings.Join(functional.Map(strings.Split(v, "/"), url.PathEscape), "/")
+}
+
+// escapeQuery query escapes the value, so that it cannot add parameters to the query
+func escapeQuery(v string) string {
+	if unescaped, err := url.PathUnescape(v); err == nil {
+		v = unescaped
+	}
+	return url.QueryEscape(v)
+}
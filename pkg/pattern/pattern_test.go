@@ -0,0 +1,86 @@
+package pattern
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCapturesFindsAllNamedParametersAndWildcard(t *testing.T) {
+	for route, expect := range map[string][]string{
+		"/blog":                          {},
+		"/blog/{year}/{slug}":            {"year", "slug"},
+		"/blog/{year:[0-9]{4}}/{slug}":   {"year", "slug"},
+		"/static/*":                      {"*"},
+		"/users/{id:[a-z]+}/files/*":     {"id", "*"},
+		"/{first}-{second:(foo|bar)}/x/": {"first", "second"},
+	} {
+		cs := Captures(route)
+		if !reflect.DeepEqual(cs, expect) {
+			t.Errorf("Captures of '%s' were %#v, expected %#v", route, cs, expect)
+		}
+	}
+}
+
+func TestPlaceholdersFindsAllPlaceholdersInOrder(t *testing.T) {
+	for template, expect := range map[string][]string{
+		"/posts":                     {},
+		"/posts/{slug}":              {"slug"},
+		"/posts/{slug}?year={year}":  {"slug", "year"},
+		"https://example.com/{*}":    {"*"},
+		"/{slug}/{slug}/{something}": {"slug", "slug", "something"},
+	} {
+		ps := Placeholders(template)
+		if !reflect.DeepEqual(ps, expect) {
+			t.Errorf("Placeholders of '%s' were %#v, expected %#v", template, ps, expect)
+		}
+	}
+}
+
+func TestExpandReplacesPlaceholdersWithEscapedValues(t *testing.T) {
+	values := map[string]string{"slug": "hello world", "year": "2022", "*": "a/b c/d", "q": "a&admin=1"}
+	for template, expect := range map[string]string{
+		"/posts":                    "/posts",
+		"/posts/{slug}":             "/posts/hello%20world",
+		"/posts/{slug}?year={year}": "/posts/hello%20world?year=2022",
+		"/search?q={q}&slug={slug}": "/search?q=a%26admin%3D1&slug=hello+world",
+		"/files/{*}":                "/files/a/b%20c/d",
+		"/{missing}":                "/",
+	} {
+		e := Expand(template, func(n string) string { return values[n] })
+		if e != expect {
+			t.Errorf("Expanded '%s' to '%s', expected '%s'", template, e, expect)
+		}
+	}
+}
+
+func TestGroupsFindsNamedGroupsOfParameterRegexps(t *testing.T) {
+	gs, err := Groups("/archive/{date:(?P<year>[0-9]{4})-(?P<month>[0-9]{2})}/{slug}/{id:([0-9]+)}")
+	if err != nil {
+		t.Fatalf("Failed to find groups: %s", err)
+	}
+	if len(gs) != 2 {
+		t.Errorf("Found groups %#v, expected only year & month", gs)
+	}
+	for name, expect := range map[string]string{"year": "2022", "month": "07"} {
+		g, ok := gs[name]
+		if !ok || g.Parameter != "date" {
+			t.Errorf("Group %s is %#v, expected it in parameter date", name, g)
+			continue
+		}
+		if v := g.Value("2022-07"); v != expect {
+			t.Errorf("Group %s captured '%s', expected '%s'", name, v, expect)
+		}
+	}
+}
+
+func TestGroupsFailsForInvalidOrRepeatedNames(t *testing.T) {
+	for _, route := range []string{
+		"/archive/{date:(?P<year>[0-9}",
+		"/archive/{year:(?P<year>[0-9]{4})}",
+		"/{a:(?P<x>[0-9])}/{b:(?P<x>[a-z])}",
+	} {
+		if gs, err := Groups(route); err == nil {
+			t.Errorf("Found groups %#v in '%s', expected an error", gs, route)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package proxy
+
+import "net/http"
+
+// HostHeader chooses the Host header of the downstream request from the incoming request,
+// where an empty string means using the downstream's host & port
+type HostHeader func(incoming *http.Request) string
+
+// DownstreamHost sends the downstream's host & port as the Host header
+func DownstreamHost() HostHeader {
+	return func(*http.Request) string { return "" }
+}
+
+// PreserveHost sends the Host header the client sent to the proxy
+func PreserveHost() HostHeader {
+	return func(incoming *http.Request) string { return incoming.Host }
+}
+
+// FixedHost always sends the given value as the Host header
+func FixedHost(host string) HostHeader {
+	return func(*http.Request) string { return host }
+}
@@ -0,0 +1,115 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+)
+
+// Limits restricts the size and duration of requests forwarded by the proxy
+type Limits struct {
+	MaxBodyBytes int64         // 0 for no limit
+	BodyTooLarge string        // message sent with the 413 response
+	MaxDuration  time.Duration // 0 for no limit
+}
+
+// tooLarge checks whether the request declares a body larger than allowed
+func (l Limits) tooLarge(req *http.Request) bool {
+	return l.MaxBodyBytes > 0 && req.ContentLength > l.MaxBodyBytes
+}
+
+// deadline returns the context for the downstream request, which is cancelled
+// if the response headers are not received within the maximum duration, if there is one
+func (l Limits) deadline(req *http.Request) (context.Context, *deadline) {
+	ctx, cancel := context.WithCancel(req.Context())
+	d := &deadline{cancel: cancel}
+	if l.MaxDuration != 0 {
+		d.timer = time.AfterFunc(l.MaxDuration, func() {
+			d.exceeded.Store(true)
+			cancel()
+		})
+	}
+	return ctx, d
+}
+
+// deadline cancels a downstream request which does not respond within the maximum duration,
+// but not once the response headers have been received, however long the body takes
+type deadline struct {
+	timer    *time.Timer
+	cancel   context.CancelFunc
+	exceeded atomic.Bool
+}
+
+// responded stops the deadline, once the response headers have been received
+func (d *deadline) responded() {
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+}
+
+// stop stops the deadline and releases the context, once the request is complete
+func (d *deadline) stop() {
+	d.responded()
+	d.cancel()
+}
+
+// body wraps the request body so that reading it fails once the maximum size has been read
+func (l Limits) body(w http.ResponseWriter, req *http.Request) *trackedBody {
+	if l.MaxBodyBytes == 0 {
+		return &trackedBody{r: req.Body}
+	}
+	return &trackedBody{r: http.MaxBytesReader(w, req.Body, l.MaxBodyBytes)}
+}
+
+// sendExceeded sends an error response if the downstream request failed
+// because a limit was exceeded, returning false if it was not
+func (l Limits) sendExceeded(
+	w http.ResponseWriter, err error, body *trackedBody, d *deadline, lg log.Logger,
+) bool {
+	var mbe *http.MaxBytesError
+	switch {
+	case errors.As(err, &mbe):
+		lg.Infof("Request body larger than the maximum %d bytes", mbe.Limit)
+		sendErrorResponse(w, http.StatusRequestEntityTooLarge, l.BodyTooLarge, lg)
+	case d.exceeded.Load() && !body.done.Load():
+		lg.Infof("Request body not received within the maximum duration %s", l.MaxDuration)
+		sendErrorResponse(w, http.StatusRequestTimeout, requestTimeoutMessage, lg)
+	case d.exceeded.Load():
+		lg.Infof("Downstream did not respond within the maximum duration %s", l.MaxDuration)
+		sendErrorResponse(w, http.StatusGatewayTimeout, gatewayTimeoutMessage, lg)
+	default:
+		return false
+	}
+	return true
+}
+
+// trackedBody wraps a request body, recording when it has been read completely
+type trackedBody struct {
+	r    io.ReadCloser
+	done atomic.Bool
+}
+
+// Read reads from the wrapped body, recording if the end has been reached
+func (b *trackedBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err == io.EOF {
+		b.done.Store(true)
+	}
+	return n, err
+}
+
+// Close closes the wrapped body
+func (b *trackedBody) Close() error {
+	return b.r.Close()
+}
+
+// requestTimeoutMessage is sent when the client takes too long to send the request
+const requestTimeoutMessage = "408: request took too long"
+
+// gatewayTimeoutMessage is sent when the downstream takes too long to respond
+const gatewayTimeoutMessage = "504: downstream took too long to respond"
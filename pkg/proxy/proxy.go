@@ -3,70 +3,151 @@ package proxy
 import (
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/snasphysicist/ferp/v2/pkg/functional"
+	"github.com/snasphysicist/ferp/v2/pkg/header"
 	"github.com/snasphysicist/ferp/v2/pkg/log"
+	"github.com/snasphysicist/ferp/v2/pkg/request"
 	"github.com/snasphysicist/ferp/v2/pkg/url"
 )
 
 // Proxy implements a HTTP handler to proxy (forward) requests
 type Proxy struct {
 	url.BaseURL
-	Mapper url.PathRewriter
+	Target          string // the name of the downstream, for the request metrics
+	Mapper          PathMapper
+	Host            HostHeader
+	RewriteResponse bool // map URLs and cookies in the response back from the downstream to the proxy
+	Limits          Limits
+	Query           []url.QueryRewriter
+	RequestHeaders  []header.Rewriter
+	ResponseHeaders []header.Rewriter
 }
 
+// PathMapper maps the path of a request to that of the downstream request, logging with the request's logger
+type PathMapper func(log.Logger, string) (string, bool)
+
 // ForwardRequest forwards the incoming request to the configured downstream
 // and writes out the received reponse to the outgoing response
 func (p Proxy) ForwardRequest(w http.ResponseWriter, req *http.Request) {
-	url := url.Rewrite(*req.URL, p.BaseURL, p.Mapper)
-	dReq, err := http.NewRequest(req.Method, url, req.Body)
+	l := log.From(req.Context())
+	url, ok := url.Rewrite(url.RewriteQuery(*req.URL, p.Query), p.BaseURL,
+		func(from string) (string, bool) { return p.Mapper(l, from) })
+	if !ok {
+		l.Infof("Path %s cannot be mapped to the downstream, not forwarding", req.URL.Path)
+		http.NotFound(w, req)
+		return
+	}
+	if p.Limits.tooLarge(req) {
+		l.Infof("Request declares %d bytes of body, more than the maximum %d",
+			req.ContentLength, p.Limits.MaxBodyBytes)
+		sendErrorResponse(w, http.StatusRequestEntityTooLarge, p.Limits.BodyTooLarge, l)
+		return
+	}
+	ctx, d := p.Limits.deadline(req)
+	defer d.stop()
+	body := p.Limits.body(w, req)
+	dReq, err := http.NewRequestWithContext(ctx, req.Method, url, body)
 	if err != nil {
-		log.L().Errorf("Failed to construct downstream request: %s", err)
-		sendInternalErrorResponse(w)
+		l.Errorf("Failed to construct downstream request: %s", err)
+		sendInternalErrorResponse(w, l)
 		return
 	}
+	dReq.Host = p.Host(req)
 	transferRequestHeaders(req, dReq)
-	res, err := (&http.Client{}).Do(dReq)
+	header.Apply(dReq.Header, p.RequestHeaders, req)
+	useHostHeader(dReq)
+	m := request.MetricsFrom(req.Context())
+	m.Target = p.Target
+	m.Upstream = dReq.URL.Host
+	sent := time.Now()
+	res, err := p.downstreamClient().Do(dReq)
+	d.responded()
+	m.UpstreamDuration = time.Since(sent)
+	if err != nil && p.Limits.sendExceeded(w, err, body, d, l) {
+		return
+	}
 	if err != nil {
-		log.L().Errorf("Failed to send downstream request: %s", err)
-		sendInternalErrorResponse(w)
+		l.Errorf("Failed to send downstream request: %s", err)
+		sendInternalErrorResponse(w, l)
 		return
 	}
 	defer func() { _ = res.Body.Close() }()
 	transferResponseHeaders(res, w)
+	if p.RewriteResponse {
+		rewriteResponseHeaders(w.Header(), newReverseMapping(req, dReq))
+	}
+	header.Apply(w.Header(), p.ResponseHeaders, req)
 	w.WriteHeader(res.StatusCode)
 	_, err = io.Copy(w, res.Body)
 	if err != nil {
-		log.L().Errorf("Failed to forward response body: %s", err)
+		l.Errorf("Failed to forward response body: %s", err)
 		return
 	}
-	log.L().Infof("Successfully proxied request from %s to %#v",
+	l.Infof("Successfully proxied request from %s to %#v",
 		req.URL.String(), dReq.URL.String())
 }
 
+// downstreamClient sends requests to the downstream. If its responses are rewritten,
+// any redirect is returned to the client as it was received, to be mapped back
+// to the proxy, instead of being followed.
+func (p Proxy) downstreamClient() *http.Client {
+	if !p.RewriteResponse {
+		return &http.Client{}
+	}
+	return &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
 // sendInternal sends an error response when something goes wrong in the proxy itself
-func sendInternalErrorResponse(w http.ResponseWriter) {
-	w.WriteHeader(http.StatusInternalServerError)
-	_, err := w.Write([]byte(internalErrorMessage))
+func sendInternalErrorResponse(w http.ResponseWriter, l log.Logger) {
+	sendErrorResponse(w, http.StatusInternalServerError, internalErrorMessage, l)
+}
+
+// sendErrorResponse sends an error response with the status and message, logging any failure
+func sendErrorResponse(w http.ResponseWriter, status int, message string, l log.Logger) {
+	w.WriteHeader(status)
+	_, err := w.Write([]byte(message))
 	if err != nil {
-		log.L().Errorf("Failed to write error response body: %s", err)
+		l.Errorf("Failed to write error response body: %s", err)
 	}
 }
 
 // transferRequestHeaders copies all headers from "from" to "to"
-// except for content-length, which must be autogenerated
+// except for content-length, which must be autogenerated,
+// and sends the request's ID in place of any it was received with
 func transferRequestHeaders(from *http.Request, to *http.Request) {
 	for k, values := range from.Header {
 		addIfAllowed(to.Header, k, values)
 	}
+	if id, ok := request.IDFrom(from.Context()); ok {
+		to.Header.Set(id.Header, id.Value)
+	}
 }
 
-// transferRequestHeaders copies all headers from "from" to "to"
-// except for content-length, which must be autogenerated
+// transferResponseHeaders copies all headers from "from" to "to"
+// except for content-length, which must be autogenerated,
+// keeping only the echoed request ID if the downstream echoes one too
 func transferResponseHeaders(from *http.Response, to http.ResponseWriter) {
 	for k, values := range from.Header {
 		addIfAllowed(to.Header(), k, values)
 	}
+	if id, ok := request.IDFrom(from.Request.Context()); ok {
+		to.Header().Set(id.Header, id.Value)
+	}
+}
+
+// useHostHeader moves any Host header set on the request into its Host field,
+// because the Host header is otherwise ignored when the request is sent
+func useHostHeader(r *http.Request) {
+	if h := r.Header.Get("Host"); h != "" {
+		r.Host = h
+	}
+	r.Header.Del("Host")
 }
 
 // transferIfAllowed adds the key and all values to the headers, if allowed
@@ -0,0 +1,133 @@
+package proxy
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+)
+
+// reverseMapping maps URLs on the downstream back to the corresponding URLs on the proxy.
+// The part of the path that was changed on forwarding is found by comparing
+// the incoming and downstream paths, so that this works whatever the path mapper.
+type reverseMapping struct {
+	downstreamHost   string
+	downstreamPrefix string
+	publicScheme     string
+	publicHost       string
+	publicPrefix     string
+	l                log.Logger // of the incoming request
+}
+
+// newReverseMapping creates the reverse mapping for the incoming
+// request and the downstream request it was forwarded as
+func newReverseMapping(incoming *http.Request, downstream *http.Request) reverseMapping {
+	suffix := commonSegmentSuffix(incoming.URL.Path, downstream.URL.Path)
+	scheme := "http"
+	if incoming.TLS != nil {
+		scheme = "https"
+	}
+	return reverseMapping{
+		downstreamHost:   downstream.URL.Host,
+		downstreamPrefix: strings.TrimSuffix(downstream.URL.Path, suffix),
+		publicScheme:     scheme,
+		publicHost:       incoming.Host,
+		publicPrefix:     strings.TrimSuffix(incoming.URL.Path, suffix),
+		l:                log.From(incoming.Context()),
+	}
+}
+
+// commonSegmentSuffix finds the longest run of whole path segments at the end of both paths
+func commonSegmentSuffix(a string, b string) string {
+	as := strings.Split(a, "/")
+	bs := strings.Split(b, "/")
+	n := 0
+	for n < len(as)-1 && n < len(bs)-1 && as[len(as)-1-n] == bs[len(bs)-1-n] {
+		n++
+	}
+	return strings.Join(as[len(as)-n:], "/")
+}
+
+// rewriteResponseHeaders maps the URLs in the Location, Content-Location & Refresh headers,
+// and the path & domain of cookies set, from the downstream back to the proxy
+func rewriteResponseHeaders(h http.Header, m reverseMapping) {
+	for _, k := range []string{"Location", "Content-Location"} {
+		if v := h.Get(k); v != "" {
+			h.Set(k, m.url(v))
+		}
+	}
+	if v := h.Get("Refresh"); v != "" {
+		h.Set("Refresh", refreshURL.ReplaceAllStringFunc(v, func(s string) string {
+			parts := refreshURL.FindStringSubmatch(s)
+			return parts[1] + m.url(parts[2])
+		}))
+	}
+	cookies := h.Values("Set-Cookie")
+	h.Del("Set-Cookie")
+	for _, c := range cookies {
+		h.Add("Set-Cookie", m.cookie(c))
+	}
+}
+
+// refreshURL matches the url in a Refresh header, capturing everything before it and the url
+var refreshURL = regexp.MustCompile(`(?i)^(\s*\d+\s*;\s*url\s*=\s*)(.*)$`)
+
+// url maps the URL, if it is an absolute path or on the downstream's or proxy's host,
+// otherwise returning it unchanged
+func (m reverseMapping) url(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		m.l.Errorf("Failed to parse URL '%s' from downstream response, not rewriting: %s", raw, err)
+		return raw
+	}
+	if u.Host == m.downstreamHost {
+		u.Scheme = m.publicScheme
+		u.Host = m.publicHost
+	}
+	if u.Host != m.publicHost && u.Host != "" {
+		return raw
+	}
+	if !strings.HasPrefix(u.Path, "/") {
+		return raw
+	}
+	u.Path = m.path(u.Path)
+	u.RawPath = ""
+	return u.String()
+}
+
+// path maps the path, if it starts with the prefix that was added when forwarding
+func (m reverseMapping) path(p string) string {
+	if !strings.HasPrefix(p, m.downstreamPrefix) {
+		return p
+	}
+	return m.publicPrefix + strings.TrimPrefix(p, m.downstreamPrefix)
+}
+
+// cookie maps the path and (if it is the downstream's host) the domain of the cookie
+func (m reverseMapping) cookie(raw string) string {
+	cs := (&http.Response{Header: http.Header{"Set-Cookie": []string{raw}}}).Cookies()
+	if len(cs) != 1 {
+		m.l.Errorf("Failed to parse cookie '%s' from downstream response, not rewriting", raw)
+		return raw
+	}
+	c := cs[0]
+	if c.Path != "" {
+		c.Path = m.path(c.Path)
+	}
+	if c.Domain != "" && strings.EqualFold(c.Domain, hostname(m.downstreamHost)) {
+		c.Domain = hostname(m.publicHost)
+	}
+	return c.String()
+}
+
+// hostname strips any port from the host
+func hostname(host string) string {
+	h, _, err := net.SplitHostPort(host)
+	if err != nil {
+		return host
+	}
+	return h
+}
@@ -0,0 +1,53 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+)
+
+func TestReverseMappingFindsPrefixesChangedOnForwarding(t *testing.T) {
+	in := httptest.NewRequest(http.MethodGet, "http://proxy.example.com/public/users/1", nil)
+	down := httptest.NewRequest(http.MethodGet, "http://10.0.0.1:8080/app/users/1", nil)
+	m := newReverseMapping(in, down)
+	if m.publicPrefix != "/public/" || m.downstreamPrefix != "/app/" {
+		t.Errorf("Found public prefix '%s' and downstream prefix '%s' from %s and %s",
+			m.publicPrefix, m.downstreamPrefix, in.URL, down.URL)
+	}
+}
+
+func TestRewritesRefreshHeaderURL(t *testing.T) {
+	m := reverseMapping{downstreamHost: "10.0.0.1:8080", downstreamPrefix: "/app/",
+		publicScheme: "https", publicHost: "proxy.example.com", publicPrefix: "/public/", l: log.Nop()}
+	h := http.Header{"Refresh": []string{"5; url=http://10.0.0.1:8080/app/done"}}
+	rewriteResponseHeaders(h, m)
+	expect := "5; url=https://proxy.example.com/public/done"
+	if h.Get("Refresh") != expect {
+		t.Errorf("Rewrote Refresh header to '%s' not '%s'", h.Get("Refresh"), expect)
+	}
+}
+
+func TestDoesNotRewriteURLsOnOtherHostsOrOutsideTheDownstreamPrefix(t *testing.T) {
+	m := reverseMapping{downstreamHost: "10.0.0.1:8080", downstreamPrefix: "/app/",
+		publicScheme: "https", publicHost: "proxy.example.com", publicPrefix: "/public/", l: log.Nop()}
+	for _, u := range []string{"https://www.example.com/app/login", "/elsewhere", "relative/path"} {
+		if r := m.url(u); r != u {
+			t.Errorf("Rewrote '%s' to '%s', expected it to be unchanged", u, r)
+		}
+	}
+}
+
+func TestRewritesCookieDomainOnlyIfItIsTheDownstreamHost(t *testing.T) {
+	m := reverseMapping{downstreamHost: "internal:8080", downstreamPrefix: "/",
+		publicScheme: "https", publicHost: "proxy.example.com:8443", publicPrefix: "/", l: log.Nop()}
+	h := http.Header{"Set-Cookie": []string{"a=1; Domain=internal", "b=2; Domain=example.org"}}
+	rewriteResponseHeaders(h, m)
+	expect := []string{"a=1; Domain=proxy.example.com", "b=2; Domain=example.org"}
+	for i, c := range h.Values("Set-Cookie") {
+		if c != expect[i] {
+			t.Errorf("Rewrote cookie to '%s' not '%s'", c, expect[i])
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package request
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ID identifies a request in the logs of the proxy and of the downstream it is forwarded to
+type ID struct {
+	Header string // the header in which the ID is received, forwarded & echoed
+	Value  string
+}
+
+// maxIDLength is the length of the longest ID accepted from a client
+const maxIDLength = 128
+
+// IDFor returns the ID in the header of the request if it is valid, else a newly generated one
+func IDFor(r *http.Request, header string) ID {
+	v := r.Header.Get(header)
+	if !ValidID(v) {
+		v = newID()
+	}
+	return ID{Header: header, Value: v}
+}
+
+// ValidID checks that an ID from a client is not empty, not too long, and only has
+// characters which are safe to write into logs and headers without escaping
+func ValidID(v string) bool {
+	if v == "" || len(v) > maxIDLength {
+		return false
+	}
+	for _, c := range v {
+		if !isIDCharacter(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// isIDCharacter checks that the character is a letter, digit or one of a few separators
+func isIDCharacter(c rune) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') ||
+		strings.ContainsRune("-_.:+/=@", c)
+}
+
+// ValidHeaderName checks that the name is a HTTP token, i.e. can be used as the name of a header
+func ValidHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if !(('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') ||
+			strings.ContainsRune("!#$%&'*+-.^_`|~", c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// generated counts the IDs generated, so that they are unique even if random bytes are unavailable
+var generated atomic.Uint64
+
+// newID generates a random 32 character hexadecimal ID
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%016x%016x", time.Now().UnixNano(), generated.Add(1))
+	}
+	return hex.EncodeToString(b)
+}
+
+// idKey is the key under which the ID of a request is stored in its context
+type idKey struct{}
+
+// WithID returns a copy of the context carrying the ID
+func WithID(ctx context.Context, id ID) context.Context {
+	return context.WithValue(ctx, idKey{}, id)
+}
+
+// IDFrom returns the ID carried by the context, if there is one
+func IDFrom(ctx context.Context) (ID, bool) {
+	id, ok := ctx.Value(idKey{}).(ID)
+	return id, ok
+}
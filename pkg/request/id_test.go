@@ -0,0 +1,53 @@
+package request
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIDForUsesValidIncomingID(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	r.Header.Set("X-Correlation-ID", "abc-123_4.5:6")
+	id := IDFor(r, "X-Correlation-ID")
+	if id.Value != "abc-123_4.5:6" || id.Header != "X-Correlation-ID" {
+		t.Errorf("ID is %+v, expected the incoming one", id)
+	}
+}
+
+func TestIDForGeneratesIDIfIncomingIsMissingOrInvalid(t *testing.T) {
+	for _, v := range []string{"", "has space", "new\nline", "quote\"", strings.Repeat("a", maxIDLength+1)} {
+		r := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		r.Header.Set("X-Request-ID", v)
+		id := IDFor(r, "X-Request-ID")
+		if id.Value == v || !ValidID(id.Value) || len(id.Value) != 32 {
+			t.Errorf("ID for incoming '%s' is '%s', expected a new one", v, id.Value)
+		}
+	}
+}
+
+func TestGeneratedIDsAreUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		id := newID()
+		if seen[id] {
+			t.Fatalf("Generated %s twice", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestValidHeaderName(t *testing.T) {
+	for name, valid := range map[string]bool{
+		"X-Request-ID": true,
+		"traceparent":  true,
+		"":             false,
+		"X Request":    false,
+		"X-Request:":   false,
+	} {
+		if ValidHeaderName(name) != valid {
+			t.Errorf("Header name '%s' valid should be %t", name, valid)
+		}
+	}
+}
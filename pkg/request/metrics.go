@@ -0,0 +1,91 @@
+package request
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics are measured while a request is handled: the response by the logging middleware,
+// the request body as it is read, and the downstream it is forwarded to by the proxy
+type Metrics struct {
+	Start            time.Time
+	Target           string        // the downstream the request was forwarded to, if any
+	Upstream         string        // the address of that downstream
+	UpstreamDuration time.Duration // until the response headers were received from the downstream
+	status           int
+	firstByte        time.Duration
+	written          int64
+	read             atomic.Int64 // request bodies may be read on another goroutine, sending them downstream
+}
+
+// NewMetrics starts measuring a request received now
+func NewMetrics() *Metrics {
+	return &Metrics{Start: time.Now()}
+}
+
+// WroteHeader records the status of the response and the time to its first byte,
+// if the headers have not already been written
+func (m *Metrics) WroteHeader(status int) {
+	if m.status != 0 {
+		return
+	}
+	m.status = status
+	m.firstByte = time.Since(m.Start)
+}
+
+// Wrote records that n more bytes of response body were written
+func (m *Metrics) Wrote(n int) {
+	m.written += int64(n)
+}
+
+// Read records that n more bytes of request body were read
+func (m *Metrics) Read(n int) {
+	m.read.Add(int64(n))
+}
+
+// Status is the status of the response, 200 if the headers have not been written,
+// since that is what is sent if the handler returns without writing them
+func (m *Metrics) Status() int {
+	if m.status == 0 {
+		return http.StatusOK
+	}
+	return m.status
+}
+
+// FirstByte is the time from receiving the request until the response headers were written,
+// or until now if they have not been, since they are sent as the handler returns
+func (m *Metrics) FirstByte() time.Duration {
+	if m.status == 0 {
+		return time.Since(m.Start)
+	}
+	return m.firstByte
+}
+
+// BytesWritten is the total size of the response body written so far
+func (m *Metrics) BytesWritten() int64 {
+	return m.written
+}
+
+// BytesRead is the total size of the request body read so far
+func (m *Metrics) BytesRead() int64 {
+	return m.read.Load()
+}
+
+// metricsKey is the key under which the metrics of a request are stored in its context
+type metricsKey struct{}
+
+// WithMetrics returns a copy of the context carrying the metrics
+func WithMetrics(ctx context.Context, m *Metrics) context.Context {
+	return context.WithValue(ctx, metricsKey{}, m)
+}
+
+// MetricsFrom returns the metrics carried by the context,
+// or, if the request is not being measured, metrics which are discarded
+func MetricsFrom(ctx context.Context) *Metrics {
+	if m, ok := ctx.Value(metricsKey{}).(*Metrics); ok {
+		return m
+	}
+	return NewMetrics()
+}
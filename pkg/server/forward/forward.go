@@ -1,15 +1,19 @@
 package forward
 
 import (
+	"net/http"
+
 	"github.com/go-chi/chi/v5"
 	"github.com/snasphysicist/ferp/v2/pkg/configuration"
+	"github.com/snasphysicist/ferp/v2/pkg/header"
 	"github.com/snasphysicist/ferp/v2/pkg/log"
+	"github.com/snasphysicist/ferp/v2/pkg/middleware"
 	"github.com/snasphysicist/ferp/v2/pkg/proxy"
 	"github.com/snasphysicist/ferp/v2/pkg/url"
 )
 
 // Configure sets up on the router all proxy routes defined in the incomings
-func Configure(r *chi.Mux, incs []configuration.Incoming) {
+func Configure(r *chi.Mux, incs []configuration.Incoming, l log.Logger) {
 	for _, i := range incs {
 		rm := proxy.Proxy{
 			BaseURL: url.BaseURL{
@@ -18,13 +22,78 @@ func Configure(r *chi.Mux, incs []configuration.Incoming) {
 				Port:     i.Downstream.Port,
 				Path:     i.Downstream.Base,
 			},
-			Mapper: i.Downstream.Mapper.Map,
+			Target:          i.Target,
+			Mapper:          i.Downstream.Mapper.MapLogged,
+			Host:            hostHeader(i.Downstream.HostHeader),
+			RewriteResponse: i.Downstream.RewriteResponses,
+			Query:           append(queryRewriters(i.Downstream.Query), queryRewriters(i.Query)...),
+			RequestHeaders: append(
+				headerRewriters(i.Downstream.Headers.Request), headerRewriters(i.Headers.Request)...),
+			ResponseHeaders: append(
+				headerRewriters(i.Downstream.Headers.Response), headerRewriters(i.Headers.Response)...),
+			Limits: proxy.Limits{
+				MaxBodyBytes: i.Limits.MaxBodyBytes,
+				BodyTooLarge: i.Limits.BodyTooLarge,
+				MaxDuration:  i.Limits.MaxDuration,
+			},
 		}
-		log.L().Infof("For Incoming %#v constructed Remapper %#v ", i, rm)
+		l.Infof("For Incoming %#v constructed Remapper %#v ", i, rm)
+		h := middleware.Deadlines(i.Timeouts.Read, i.Timeouts.Write)(http.HandlerFunc(rm.ForwardRequest))
+		h = middleware.Route(i.Path)(h)
 		for _, mr := range i.MethodRouters {
-			log.L().Infof("Configuring remapper %#v for incoming '%s' with %#v",
+			l.Infof("Configuring remapper %#v for incoming '%s' with %#v",
 				rm, i.Path, mr)
-			mr.Route(r, i.Path, rm.ForwardRequest)
+			mr.Route(r, i.Path, h.ServeHTTP)
 		}
 	}
 }
+
+// queryRewriters creates a query rewriter for each of the (validated) query rules
+func queryRewriters(qrs []configuration.QueryRule) []url.QueryRewriter {
+	rws := make([]url.QueryRewriter, 0)
+	for _, qr := range qrs {
+		switch qr.Action {
+		case configuration.QuerySet:
+			rws = append(rws, url.SetQueryParameter(qr.Name, qr.Value))
+		case configuration.QueryAdd:
+			rws = append(rws, url.AddQueryParameter(qr.Name, qr.Value))
+		case configuration.QueryRemove:
+			rws = append(rws, url.RemoveQueryParameter(qr.Name))
+		case configuration.QueryRename:
+			rws = append(rws, url.RenameQueryParameter(qr.Name, qr.To))
+		case configuration.QueryDropAll:
+			rws = append(rws, url.DropQuery())
+		}
+	}
+	return rws
+}
+
+// headerRewriters creates a header rewriter for each of the (validated) header rules
+func headerRewriters(hrs []configuration.HeaderRule) []header.Rewriter {
+	rws := make([]header.Rewriter, 0)
+	for _, hr := range hrs {
+		switch hr.Action {
+		case configuration.HeaderSet:
+			rws = append(rws, header.Set(hr.Name, hr.Value, hr.Env))
+		case configuration.HeaderAdd:
+			rws = append(rws, header.Add(hr.Name, hr.Value, hr.Env))
+		case configuration.HeaderRemove:
+			rws = append(rws, header.Remove(hr.Name))
+		case configuration.HeaderRename:
+			rws = append(rws, header.Rename(hr.Name, hr.To))
+		}
+	}
+	return rws
+}
+
+// hostHeader creates the host header chooser for the (validated) host header configuration
+func hostHeader(hh configuration.HostHeader) proxy.HostHeader {
+	switch hh.Policy {
+	case configuration.HostPreserve:
+		return proxy.PreserveHost()
+	case configuration.HostFixed:
+		return proxy.FixedHost(hh.Value)
+	default:
+		return proxy.DownstreamHost()
+	}
+}
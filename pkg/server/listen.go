@@ -0,0 +1,256 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/snasphysicist/ferp/v2/pkg/configuration"
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+	"github.com/snasphysicist/ferp/v2/pkg/systemd"
+)
+
+// Listeners opens listeners for the servers, reusing those inherited from the process
+// which started this one where the server name & address match, and keeps track of
+// all of them so that they can be handed on to another process. Listeners inherited
+// from socket activation have no address, and are used instead of any configured.
+type Listeners struct {
+	inherited []namedListener
+	open      []namedListener
+	l         log.Logger
+}
+
+// namedListener is a listener on the address for the named server
+type namedListener struct {
+	Server   string `json:"server"`
+	Address  string `json:"address"`
+	listener net.Listener
+}
+
+// Listen opens a listener on each of the addresses, or on all interfaces at the port if there are none.
+// If any cannot be opened, those already opened are closed and an error is returned.
+func (ls *Listeners) Listen(server string, port uint16, las []configuration.Listen) ([]net.Listener, error) {
+	if nls := ls.takeActivated(server); len(nls) != 0 {
+		return nls, nil
+	}
+	if len(las) == 0 {
+		las = []configuration.Listen{{Address: fmt.Sprintf("0.0.0.0:%d", port)}}
+	}
+	nls := make([]net.Listener, 0)
+	for _, la := range las {
+		nl, ok := ls.takeInherited(server, la.Address)
+		if !ok {
+			var err error
+			nl, err = listen(la)
+			if err != nil {
+				closeListeners(nls, ls.l)
+				return nil, fmt.Errorf("failed to listen on %s: %s", la.Address, err)
+			}
+		}
+		nls = append(nls, nl)
+		ls.open = append(ls.open, namedListener{Server: server, Address: la.Address, listener: nl})
+	}
+	return nls, nil
+}
+
+// takeInherited removes & returns the inherited listener for the server on the address, if there is one
+func (ls *Listeners) takeInherited(server string, address string) (net.Listener, bool) {
+	for i, nl := range ls.inherited {
+		if nl.Server == server && nl.Address == address {
+			ls.inherited = append(ls.inherited[:i], ls.inherited[i+1:]...)
+			ls.l.Infof("Using inherited listener for server %s on %s", server, address)
+			return nl.listener, true
+		}
+	}
+	return nil, false
+}
+
+// takeActivated removes & returns all the listeners from socket activation for the server
+func (ls *Listeners) takeActivated(server string) []net.Listener {
+	nls := make([]net.Listener, 0)
+	remaining := make([]namedListener, 0)
+	for _, nl := range ls.inherited {
+		if nl.Server != server || nl.Address != "" {
+			remaining = append(remaining, nl)
+			continue
+		}
+		ls.l.Infof("Using socket activated listener on %s for server %s", nl.listener.Addr(), server)
+		nls = append(nls, nl.listener)
+		ls.open = append(ls.open, nl)
+	}
+	ls.inherited = remaining
+	return nls
+}
+
+// CloseUnused closes any inherited listeners which no server has used,
+// e.g. because the address was removed from the configuration
+func (ls *Listeners) CloseUnused() {
+	for _, nl := range ls.inherited {
+		ls.l.Infof("Closing inherited listener for server '%s' on %s, not used by any server",
+			nl.Server, nl.listener.Addr())
+		closeListeners([]net.Listener{nl.listener}, ls.l)
+	}
+	ls.inherited = nil
+}
+
+// listen opens a listener on the unix socket or TCP address
+func listen(l configuration.Listen) (net.Listener, error) {
+	path, ok := strings.CutPrefix(l.Address, configuration.UnixPrefix)
+	if !ok {
+		return net.Listen("tcp", l.Address)
+	}
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+	if l.Permissions == 0 {
+		return net.Listen("unix", path)
+	}
+	// the socket is created with the configured permissions by masking out all others,
+	// rather than changed once created, so it is never more accessible than configured.
+	// The umask applies to the whole process, but listeners are opened before serving.
+	old := syscall.Umask(int(^l.Permissions & os.ModePerm))
+	defer syscall.Umask(old)
+	return net.Listen("unix", path)
+}
+
+// removeStaleSocket removes a socket left behind at the path by a previous run which was not
+// shut down cleanly, since it prevents listening, but refuses to remove anything else there
+func removeStaleSocket(path string) error {
+	fi, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if fi.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+	return os.Remove(path)
+}
+
+// closeListeners closes all the listeners, logging any errors encountered with the logger
+func closeListeners(ls []net.Listener, lg log.Logger) {
+	for _, l := range ls {
+		if err := l.Close(); err != nil {
+			lg.Errorf("Failed to close listener on %s: %s", l.Addr(), err)
+		}
+	}
+}
+
+// EnvInheritedListeners describes, as JSON, the listeners passed to a process as extra files
+const EnvInheritedListeners = "FERP_INHERITED_LISTENERS"
+
+// InheritListeners takes the listeners passed from the process which started this one, if any,
+// either by systemd socket activation (named for the servers) or by an upgrade, logging with the logger
+func InheritListeners(l log.Logger) (*Listeners, error) {
+	names, err := systemd.ListenFDNames()
+	if err != nil {
+		return nil, err
+	}
+	if len(names) != 0 {
+		nls := make([]namedListener, 0)
+		files := make([]*os.File, 0)
+		for i, n := range names {
+			nls = append(nls, namedListener{Server: n})
+			files = append(files, os.NewFile(uintptr(systemd.ListenFDsStart+i), n))
+		}
+		return inherit(nls, files, l)
+	}
+	desc, ok := os.LookupEnv(EnvInheritedListeners)
+	if !ok {
+		return &Listeners{l: l}, nil
+	}
+	// so that processes started by this one do not think they inherit them too
+	if err := os.Unsetenv(EnvInheritedListeners); err != nil {
+		return nil, err
+	}
+	nls := make([]namedListener, 0)
+	if err := json.Unmarshal([]byte(desc), &nls); err != nil {
+		return nil, fmt.Errorf("failed to parse inherited listeners '%s': %s", desc, err)
+	}
+	files := make([]*os.File, 0)
+	for i, nl := range nls {
+		// extra files start after stdin, stdout & stderr, like those from socket activation
+		files = append(files, os.NewFile(uintptr(systemd.ListenFDsStart+i), fmt.Sprintf("%s %s", nl.Server, nl.Address)))
+	}
+	return inherit(nls, files, l)
+}
+
+// inherit creates listeners from the files, which are described by the named listeners
+func inherit(nls []namedListener, files []*os.File, lg log.Logger) (*Listeners, error) {
+	ls := &Listeners{l: lg}
+	for i, nl := range nls {
+		l, err := net.FileListener(files[i])
+		if err != nil {
+			ls.CloseUnused()
+			return nil, fmt.Errorf("failed to inherit listener for server %s: %s", nl.Server, err)
+		}
+		// FileListener duplicates the file, the original is no longer needed
+		if err := files[i].Close(); err != nil {
+			ls.l.Errorf("Failed to close inherited file for server %s: %s", nl.Server, err)
+		}
+		if ul, ok := l.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(true)
+		}
+		nl.listener = l
+		ls.inherited = append(ls.inherited, nl)
+	}
+	return ls, nil
+}
+
+// HandOff returns the files of all open listeners and the description of them which
+// should be passed to a new process in EnvInheritedListeners. Unix sockets will
+// no longer be removed when closed, since the new process will still be using them,
+// unless the listeners are taken back.
+func (ls *Listeners) HandOff() ([]*os.File, string, error) {
+	files := make([]*os.File, 0)
+	for _, nl := range ls.open {
+		fl, ok := nl.listener.(interface{ File() (*os.File, error) })
+		if !ok {
+			CloseFiles(files, ls.l)
+			return nil, "", fmt.Errorf("listener for server %s on %s cannot be handed off", nl.Server, nl.Address)
+		}
+		f, err := fl.File()
+		if err != nil {
+			CloseFiles(files, ls.l)
+			return nil, "", fmt.Errorf("failed to get file of listener for server %s on %s: %s",
+				nl.Server, nl.Address, err)
+		}
+		files = append(files, f)
+	}
+	desc, err := json.Marshal(ls.open)
+	if err != nil {
+		CloseFiles(files, ls.l)
+		return nil, "", err
+	}
+	ls.setUnlinkOnClose(false)
+	return files, string(desc), nil
+}
+
+// TakeBack undoes HandOff, if the new process fails to take over the listeners,
+// so that unix sockets will be removed when closed again
+func (ls *Listeners) TakeBack() {
+	ls.setUnlinkOnClose(true)
+}
+
+// setUnlinkOnClose sets whether the sockets of all open unix listeners are removed when they are closed
+func (ls *Listeners) setUnlinkOnClose(unlink bool) {
+	for _, nl := range ls.open {
+		if ul, ok := nl.listener.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(unlink)
+		}
+	}
+}
+
+// CloseFiles closes all the files, logging any errors encountered with the logger
+func CloseFiles(fs []*os.File, l log.Logger) {
+	for _, f := range fs {
+		if err := f.Close(); err != nil {
+			l.Errorf("Failed to close %s: %s", f.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package server
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/snasphysicist/ferp/v2/pkg/configuration"
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+)
+
+func TestHandedOffListenersAreInheritedByServerAndAddress(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "ferp.sock")
+	addresses := []configuration.Listen{{Address: "127.0.0.1:0"}, {Address: configuration.UnixPrefix + socket}}
+	old := &Listeners{l: log.Nop()}
+	ols, err := old.Listen("http", 0, addresses)
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	files, desc, err := old.HandOff()
+	if err != nil {
+		t.Fatalf("Failed to hand off listeners: %s", err)
+	}
+	closeListeners(ols, log.Nop())
+
+	nls := make([]namedListener, 0)
+	for _, l := range addresses {
+		nls = append(nls, namedListener{Server: "http", Address: l.Address})
+	}
+	inherited, err := inherit(nls, files, log.Nop())
+	if err != nil {
+		t.Fatalf("Failed to inherit listeners described by %s: %s", desc, err)
+	}
+	ils, err := inherited.Listen("http", 0, addresses)
+	if err != nil {
+		t.Fatalf("Failed to listen with inherited listeners: %s", err)
+	}
+	defer closeListeners(ils, log.Nop())
+	if len(inherited.inherited) != 0 {
+		t.Errorf("Listeners %+v were inherited but not used", inherited.inherited)
+	}
+	for i, l := range ils {
+		if l.Addr().String() != ols[i].Addr().String() {
+			t.Errorf("Inherited listener on %s, expected %s", l.Addr(), ols[i].Addr())
+		}
+		c, err := net.Dial(l.Addr().Network(), l.Addr().String())
+		if err != nil {
+			t.Errorf("Failed to connect to inherited listener on %s: %s", l.Addr(), err)
+			continue
+		}
+		_ = c.Close()
+	}
+}
+
+func TestInheritedListenersForOtherServersAreNotUsed(t *testing.T) {
+	old := &Listeners{l: log.Nop()}
+	ols, err := old.Listen("internal", 0, []configuration.Listen{{Address: "127.0.0.1:0"}})
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	files, _, err := old.HandOff()
+	if err != nil {
+		t.Fatalf("Failed to hand off listeners: %s", err)
+	}
+	closeListeners(ols, log.Nop())
+
+	inherited, err := inherit([]namedListener{{Server: "internal", Address: "127.0.0.1:0"}}, files, log.Nop())
+	if err != nil {
+		t.Fatalf("Failed to inherit listeners: %s", err)
+	}
+	ils, err := inherited.Listen("http", 0, []configuration.Listen{{Address: "127.0.0.1:0"}})
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	defer closeListeners(ils, log.Nop())
+	if ils[0].Addr().String() == ols[0].Addr().String() {
+		t.Errorf("Server http used the listener inherited for server internal")
+	}
+	inherited.CloseUnused()
+}
+
+func TestSocketActivatedListenersUsedInsteadOfConfiguredAddresses(t *testing.T) {
+	activated, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	f, err := activated.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatalf("Failed to get file of listener: %s", err)
+	}
+	closeListeners([]net.Listener{activated}, log.Nop())
+
+	ls, err := inherit([]namedListener{{Server: "http"}}, []*os.File{f}, log.Nop())
+	if err != nil {
+		t.Fatalf("Failed to inherit listeners: %s", err)
+	}
+	nls, err := ls.Listen("http", 0, []configuration.Listen{{Address: "127.0.0.1:0"}})
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	defer closeListeners(nls, log.Nop())
+	if len(nls) != 1 || nls[0].Addr().String() != activated.Addr().String() {
+		t.Errorf("Listening on %v, expected only the activated listener on %s", nls, activated.Addr())
+	}
+}
+
+func TestListenReplacesStaleSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "ferp.sock")
+	stale, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	// leaves the socket behind, like a process which was not shut down cleanly
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	closeListeners([]net.Listener{stale}, log.Nop())
+
+	nl, err := listen(configuration.Listen{Address: configuration.UnixPrefix + socket})
+	if err != nil {
+		t.Fatalf("Failed to listen where a stale socket was left: %s", err)
+	}
+	closeListeners([]net.Listener{nl}, log.Nop())
+}
+
+func TestListenLeavesRegularFileAtSocketPathAlone(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ferp.yaml")
+	if err := os.WriteFile(path, []byte("important"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %s", err)
+	}
+
+	nl, err := listen(configuration.Listen{Address: configuration.UnixPrefix + path})
+	if err == nil {
+		closeListeners([]net.Listener{nl}, log.Nop())
+		t.Errorf("Listened at %s, expected the regular file there to prevent it", path)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil || string(b) != "important" {
+		t.Errorf("File at socket path is now '%s' (%v), expected it to be left alone", b, err)
+	}
+}
+
+func TestUnixSocketIsCreatedWithConfiguredMode(t *testing.T) {
+	old := syscall.Umask(0)
+	defer syscall.Umask(old)
+	socket := filepath.Join(t.TempDir(), "ferp.sock")
+
+	nl, err := listen(configuration.Listen{Address: configuration.UnixPrefix + socket, Permissions: 0o600})
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	defer closeListeners([]net.Listener{nl}, log.Nop())
+	fi, err := os.Stat(socket)
+	if err != nil {
+		t.Fatalf("Failed to stat socket: %s", err)
+	}
+	if fi.Mode().Perm() != 0o600 {
+		t.Errorf("Socket was created with mode %o, expected 600", fi.Mode().Perm())
+	}
+	if umask := syscall.Umask(0); umask != 0 {
+		t.Errorf("Umask is %o after listening, expected it to be restored to 0", umask)
+	}
+}
+
+func TestTakenBackSocketsAreRemovedOnClose(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "ferp.sock")
+	ls := &Listeners{l: log.Nop()}
+	nls, err := ls.Listen("http", 0, []configuration.Listen{{Address: configuration.UnixPrefix + socket}})
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	files, _, err := ls.HandOff()
+	if err != nil {
+		t.Fatalf("Failed to hand off listeners: %s", err)
+	}
+	CloseFiles(files, log.Nop())
+
+	ls.TakeBack()
+	closeListeners(nls, log.Nop())
+
+	if _, err := os.Lstat(socket); !os.IsNotExist(err) {
+		t.Errorf("Socket %s still exists after close (%v), expected it to be removed", socket, err)
+	}
+}
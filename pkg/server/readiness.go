@@ -0,0 +1,32 @@
+package server
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+)
+
+// Readiness reports on its path whether the proxy is ready to serve
+// requests, so that load balancers can stop sending requests before shutdown
+type Readiness struct {
+	Path     string // not served if empty
+	stopping atomic.Bool
+}
+
+// Stop makes the readiness checks fail from now on
+func (rd *Readiness) Stop() {
+	rd.stopping.Store(true)
+}
+
+// ServeHTTP responds 200 while the proxy is ready, or 503 once it is stopping
+func (rd *Readiness) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	status, message := http.StatusOK, "ready"
+	if rd.stopping.Load() {
+		status, message = http.StatusServiceUnavailable, "stopping"
+	}
+	w.WriteHeader(status)
+	if _, err := w.Write([]byte(message)); err != nil {
+		log.From(r.Context()).Errorf("Failed to write readiness response body: %s", err)
+	}
+}
@@ -0,0 +1,134 @@
+package redirect
+
+import (
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-chi/chi/v5"
+	"github.com/snasphysicist/ferp/v2/pkg/configuration"
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+)
+
+// ConfigureMaps sets up the router to serve the redirects from all the maps for any
+// path not matched by another route, reloading each map whenever its file changes.
+// The returned function stops watching the files and should be called on shutdown.
+func ConfigureMaps(r *chi.Mux, rms []configuration.RedirectMap, l log.Logger) func() {
+	stops := make([]func(), 0)
+	for _, rm := range rms {
+		t := &table{entries: rm.Entries, l: l.With("redirect-map", rm.File)}
+		r.NotFound(t.handler(r.NotFoundHandler()))
+		stops = append(stops, t.watch(rm))
+		l.Infof("Configuring %d redirects from map %s", len(rm.Entries), rm.File)
+	}
+	return func() {
+		for _, s := range stops {
+			s()
+		}
+	}
+}
+
+// table holds the current redirects from a redirect map, keyed by the path they redirect from
+type table struct {
+	lock    sync.RWMutex
+	entries map[string]configuration.Redirect
+	l       log.Logger // for reloads of the map
+}
+
+// handler serves a redirect if the table contains one for the path
+// of a GET or HEAD request, otherwise passes the request on to next
+func (t *table) handler(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rd, ok := t.lookup(r.URL.Path)
+		if !ok || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+			next(w, r)
+			return
+		}
+		redirector(rd)(w, r)
+	}
+}
+
+// lookup finds the redirect from the given path, if there is one
+func (t *table) lookup(path string) (configuration.Redirect, bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	rd, ok := t.entries[path]
+	return rd, ok
+}
+
+// replace swaps all the redirects in the table for the provided ones
+func (t *table) replace(entries map[string]configuration.Redirect) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.entries = entries
+}
+
+// watch reloads the table from the map's file once it has stopped changing, keeping
+// the current redirects if the new ones are invalid, until the returned function is called
+func (t *table) watch(rm configuration.RedirectMap) func() {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		t.l.Errorf("Failed to watch redirect map %s, it will not be reloaded: %s", rm.File, err)
+		return func() {}
+	}
+	// watching the directory catches files replaced by editors, not just written in place
+	if err := w.Add(filepath.Dir(rm.File)); err != nil {
+		t.l.Errorf("Failed to watch redirect map %s, it will not be reloaded: %s", rm.File, err)
+		closeWatcher(w, t.l)
+		return func() {}
+	}
+	go func() {
+		// delays the reload until the file has not changed for reloadAfter,
+		// so that a file written in several parts is not loaded half written
+		var pending *time.Timer
+		for {
+			select {
+			case e, ok := <-w.Events:
+				if !ok {
+					if pending != nil {
+						pending.Stop()
+					}
+					return
+				}
+				if filepath.Clean(e.Name) != filepath.Clean(rm.File) ||
+					e.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+					continue
+				}
+				if pending == nil {
+					pending = time.AfterFunc(reloadAfter, func() { t.reload(rm) })
+					continue
+				}
+				pending.Reset(reloadAfter)
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				t.l.Errorf("Error watching redirect map %s: %s", rm.File, err)
+			}
+		}
+	}()
+	return func() { closeWatcher(w, t.l) }
+}
+
+// reloadAfter is how long a redirect map's file must stop changing for before it is reloaded
+const reloadAfter = 200 * time.Millisecond
+
+// reload attempts to load the map's file into the table, keeping the current redirects on failure
+func (t *table) reload(rm configuration.RedirectMap) {
+	lrm, err := configuration.LoadRedirectMap(rm)
+	if err != nil {
+		t.l.Errorf("Not reloading redirect map %s: %s", rm.File, err)
+		return
+	}
+	t.replace(lrm.Entries)
+	t.l.Infof("Reloaded %d redirects from map %s", len(lrm.Entries), rm.File)
+}
+
+// closeWatcher closes the watcher, logging any error encountered
+func closeWatcher(w *fsnotify.Watcher, l log.Logger) {
+	if err := w.Close(); err != nil {
+		l.Errorf("Failed to close watcher %#v: %s", w, err)
+	}
+}
@@ -0,0 +1,98 @@
+package redirect
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/snasphysicist/ferp/v2/pkg/configuration"
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRedirectMapIsReloadedWhenFileChanges(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "map.csv")
+	writeFile(t, f, "/a,/b\n")
+	rm, err := configuration.LoadRedirectMap(configuration.RedirectMap{File: f})
+	if err != nil {
+		t.Fatalf("Failed to load redirect map: %s", err)
+	}
+	r := chi.NewRouter()
+	stop := ConfigureMaps(r, []configuration.RedirectMap{rm}, log.Nop())
+	defer stop()
+
+	if l := locationFor(r, "/a"); l != "/b" {
+		t.Fatalf("Redirected /a to '%s' before reload, expected /b", l)
+	}
+	writeFile(t, f, "/a,/c\n")
+	deadline := time.Now().Add(5 * time.Second)
+	for locationFor(r, "/a") != "/c" {
+		if time.Now().After(deadline) {
+			t.Fatalf("Redirect for /a was not reloaded from changed file")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRedirectMapWrittenInPartsIsReloadedOnceComplete(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "map.csv")
+	writeFile(t, f, "/a,/b\n/x,/y\n")
+	rm, err := configuration.LoadRedirectMap(configuration.RedirectMap{File: f})
+	if err != nil {
+		t.Fatalf("Failed to load redirect map: %s", err)
+	}
+	core, logs := observer.New(zapcore.InfoLevel)
+	r := chi.NewRouter()
+	stop := ConfigureMaps(r, []configuration.RedirectMap{rm}, log.New(zap.New(core)))
+	defer stop()
+
+	writeFile(t, f, "/a,/c\n")
+	time.Sleep(reloadAfter / 4)
+	appendFile(t, f, "/x,/z\n")
+	deadline := time.Now().Add(5 * time.Second)
+	for locationFor(r, "/x") != "/z" {
+		if time.Now().After(deadline) {
+			t.Fatalf("Redirect for /x was not reloaded from changed file")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// any further reload would have happened by now
+	time.Sleep(2 * reloadAfter)
+	reloads := logs.Filter(func(e observer.LoggedEntry) bool { return strings.HasPrefix(e.Message, "Reloaded") })
+	if n := reloads.Len(); n != 1 {
+		t.Errorf("Reloaded redirect map %d times, expected once after it was completely written: %#v", n, logs.All())
+	}
+}
+
+// locationFor sends a GET request to the path through the router, returning the location header
+func locationFor(r http.Handler, path string) string {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+	return w.Header().Get("Location")
+}
+
+// writeFile writes the content to the file at the path, failing the test on error
+func writeFile(t *testing.T, path string, content string) {
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %s", path, err)
+	}
+}
+
+// appendFile appends the content to the file at the path, failing the test on error
+func appendFile(t *testing.T, path string, content string) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %s", path, err)
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatalf("Failed to append to %s: %s", path, err)
+	}
+}
@@ -2,26 +2,79 @@ package redirect
 
 import (
 	"net/http"
+	"net/url"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/snasphysicist/ferp/v2/pkg/configuration"
 	"github.com/snasphysicist/ferp/v2/pkg/log"
+	"github.com/snasphysicist/ferp/v2/pkg/middleware"
+	"github.com/snasphysicist/ferp/v2/pkg/pattern"
 )
 
 // Configure sets up all redirects specified in configuration on the provided router
-func Configure(r *chi.Mux, rds []configuration.Redirect) {
+func Configure(r *chi.Mux, rds []configuration.Redirect, l log.Logger) {
 	for _, rd := range rds {
 		for _, mr := range rd.MethodRouters {
-			mr.Route(r, rd.From, redirector(rd.To))
-			log.L().Infof("Configuring redirect from '%s' to '%s' with %#v", rd.From, rd.To, mr)
+			mr.Route(r, rd.From, middleware.Route(rd.From)(http.HandlerFunc(redirector(rd))).ServeHTTP)
+			l.Infof("Configuring redirect from '%s' to '%s' (%d, query %s) with %#v",
+				rd.From, rd.To, rd.Status, rd.Query, mr)
 		}
 	}
 }
 
-// redirector creates a HTTP handler which returns a redirect (302) to the provided URL
-func redirector(to string) func(http.ResponseWriter, *http.Request) {
+// redirector creates a HTTP handler which returns a redirect with the configured status
+// to the configured URL, with any placeholders filled from the URL parameters (or the
+// named groups of their regexps) captured from the request path, and handling the
+// incoming query string as configured
+func redirector(rd configuration.Redirect) func(http.ResponseWriter, *http.Request) {
+	// validated on configuration load
+	groups, _ := pattern.Groups(rd.From)
 	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("location", to)
-		w.WriteHeader(http.StatusFound)
+		to := pattern.Expand(rd.To, func(name string) string {
+			if g, ok := groups[name]; ok {
+				return g.Value(chi.URLParam(r, g.Parameter))
+			}
+			return chi.URLParam(r, name)
+		})
+		w.Header().Set("location", location(to, rd.Query, *r.URL, log.From(r.Context())))
+		if isPermanent(rd.Status) {
+			w.Header().Set("cache-control", permanentCacheControl)
+		}
+		w.WriteHeader(rd.Status)
+	}
+}
+
+// location determines the value of the location header for a redirect to the target,
+// handling the query string of the incoming URL according to the query option
+func location(to string, query string, incoming url.URL, l log.Logger) string {
+	if query == configuration.QueryDrop {
+		return to
+	}
+	target, err := url.Parse(to)
+	if err != nil {
+		l.Errorf("Failed to parse redirect target '%s', not handling query: %s", to, err)
+		return to
 	}
+	switch query {
+	case configuration.QueryPreserve:
+		target.RawQuery = incoming.RawQuery
+	case configuration.QueryMerge:
+		merged := target.Query()
+		for k, vs := range incoming.Query() {
+			for _, v := range vs {
+				merged.Add(k, v)
+			}
+		}
+		target.RawQuery = merged.Encode()
+	}
+	return target.String()
 }
+
+// isPermanent is true iff the status code indicates a permanent redirect
+func isPermanent(status int) bool {
+	return status == http.StatusMovedPermanently || status == http.StatusPermanentRedirect
+}
+
+// permanentCacheControl is the cache-control header sent with permanent redirects.
+// Clients may cache these for a day, limiting the damage a mistaken redirect can do.
+const permanentCacheControl = "public, max-age=86400"
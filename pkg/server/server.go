@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/snasphysicist/ferp/v2/pkg/access"
+	"github.com/snasphysicist/ferp/v2/pkg/configuration"
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+	"github.com/snasphysicist/ferp/v2/pkg/middleware"
+	"github.com/snasphysicist/ferp/v2/pkg/server/forward"
+	"github.com/snasphysicist/ferp/v2/pkg/server/redirect"
+)
+
+// Server is a proxy server, which counts the requests it is handling
+type Server struct {
+	*http.Server
+	inFlight *atomic.Int64
+}
+
+// InFlight returns the number of requests the server is currently handling
+func (s Server) InFlight() int64 {
+	return s.inFlight.Load()
+}
+
+// New sets up the proxy server, identifying requests as configured, writing to the access log
+// if it is enabled and logging with the logger, ready for serving on the listeners from Listen
+func New(c configuration.Server, rid configuration.RequestID, rd *Readiness, al *access.Log, l log.Logger) Server {
+	l = l.With("server", c.Name)
+	inFlight := &atomic.Int64{}
+	r := middleware.RouterWithDefaults(l, rid.Header)
+	r.Use(middleware.AccessLog(al))
+	r.Use(middleware.CountInFlight(inFlight))
+	r.Use(middleware.LimitHeaders(c.Limits.MaxHeaders))
+	if rd.Path != "" {
+		r.Method(http.MethodGet, rd.Path, rd)
+	}
+	redirect.Configure(r, c.Redirects, l)
+	forward.Configure(r, c.Incoming, l)
+	stopWatching := redirect.ConfigureMaps(r, c.RedirectMaps, l)
+	s := &http.Server{
+		Handler:           r,
+		MaxHeaderBytes:    c.Limits.MaxHeaderBytes,
+		ReadHeaderTimeout: c.Timeouts.ReadHeader,
+		ReadTimeout:       c.Timeouts.Read,
+		WriteTimeout:      c.Timeouts.Write,
+		IdleTimeout:       c.Timeouts.Idle,
+	}
+	s.RegisterOnShutdown(stopWatching)
+	return Server{Server: s, inFlight: inFlight}
+}
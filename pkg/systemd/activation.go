@@ -0,0 +1,45 @@
+package systemd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ListenFDsStart is the first file descriptor passed by socket activation
+const ListenFDsStart = 3
+
+// ListenFDNames returns the names of the file descriptors passed to this process
+// by socket activation (FileDescriptorName in the socket unit, "unknown" if
+// not set), in order from ListenFDsStart, or none if there was no socket activation.
+// The environment variables describing them are unset, so that processes
+// started by this one do not think they have been passed them too.
+func ListenFDNames() ([]string, error) {
+	pid, ok := os.LookupEnv("LISTEN_PID")
+	if !ok {
+		return []string{}, nil
+	}
+	fds := os.Getenv("LISTEN_FDS")
+	names := os.Getenv("LISTEN_FDNAMES")
+	for _, e := range []string{"LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"} {
+		if err := os.Unsetenv(e); err != nil {
+			return nil, err
+		}
+	}
+	if pid != strconv.Itoa(os.Getpid()) {
+		return []string{}, nil
+	}
+	n, err := strconv.Atoi(fds)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS '%s'", fds)
+	}
+	ns := strings.Split(names, ":")
+	if names == "" {
+		ns = []string{}
+	}
+	for len(ns) < n {
+		ns = append(ns, "unknown")
+	}
+	return ns[:n], nil
+}
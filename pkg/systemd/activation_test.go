@@ -0,0 +1,37 @@
+package systemd
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestListenFDNamesFromEnvironmentWhichIsThenUnset(t *testing.T) {
+	t.Setenv("LISTEN_PID", fmt.Sprint(os.Getpid()))
+	t.Setenv("LISTEN_FDS", "3")
+	t.Setenv("LISTEN_FDNAMES", "http:internal")
+	names, err := ListenFDNames()
+	if err != nil {
+		t.Fatalf("Failed to get listen fd names: %s", err)
+	}
+	expect := []string{"http", "internal", "unknown"}
+	if !reflect.DeepEqual(names, expect) {
+		t.Errorf("Listen fd names %v, expected %v", names, expect)
+	}
+	if _, ok := os.LookupEnv("LISTEN_FDS"); ok {
+		t.Errorf("LISTEN_FDS was not unset")
+	}
+}
+
+func TestNoListenFDNamesForOtherProcess(t *testing.T) {
+	t.Setenv("LISTEN_PID", "1")
+	t.Setenv("LISTEN_FDS", "1")
+	names, err := ListenFDNames()
+	if err != nil {
+		t.Fatalf("Failed to get listen fd names: %s", err)
+	}
+	if len(names) != 0 {
+		t.Errorf("Listen fd names %v for process 1, expected none", names)
+	}
+}
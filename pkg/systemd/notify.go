@@ -0,0 +1,84 @@
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+)
+
+// The states which can be sent to systemd with Notify
+const (
+	Ready    = "READY=1"
+	Stopping = "STOPPING=1"
+	Watchdog = "WATCHDOG=1"
+)
+
+// MainPID is the state telling systemd that this process is now the service's main process,
+// which it must be told when a process started by an upgrade takes over
+func MainPID() string {
+	return fmt.Sprintf("MAINPID=%d", os.Getpid())
+}
+
+// Notify sends the states to systemd, if it started this process with a notification socket
+func Notify(states ...string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+	// a name starting with @ is an abstract socket, which Go handles
+	c, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("failed to connect to notification socket %s: %s", socket, err)
+	}
+	defer func() { _ = c.Close() }()
+	if _, err := c.Write([]byte(strings.Join(states, "\n"))); err != nil {
+		return fmt.Errorf("failed to notify %v on %s: %s", states, socket, err)
+	}
+	return nil
+}
+
+// WatchdogInterval returns how often systemd expects the watchdog to be notified,
+// or false if the watchdog is not enabled for this process, logging if its configuration is invalid
+func WatchdogInterval(l log.Logger) (time.Duration, bool) {
+	usec := os.Getenv("WATCHDOG_USEC")
+	if usec == "" {
+		return 0, false
+	}
+	// the watchdog is for the main process, which may have started this one in an upgrade
+	pid := os.Getenv("WATCHDOG_PID")
+	if pid != "" && pid != strconv.Itoa(os.Getpid()) && pid != strconv.Itoa(os.Getppid()) {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(usec, 10, 64)
+	if err != nil || n <= 0 {
+		l.Errorf("Invalid WATCHDOG_USEC '%s', not notifying the watchdog", usec)
+		return 0, false
+	}
+	return time.Duration(n) * time.Microsecond, true
+}
+
+// RunWatchdog notifies the watchdog, if enabled, at half the interval
+// systemd expects until stop is closed, logging any failures with the logger
+func RunWatchdog(stop <-chan struct{}, l log.Logger) {
+	interval, ok := WatchdogInterval(l)
+	if !ok {
+		return
+	}
+	t := time.NewTicker(interval / 2)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if err := Notify(Watchdog); err != nil {
+				l.Errorf("Failed to notify watchdog: %s", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/snasphysicist/ferp/v2/pkg/log"
+)
+
+func TestNotifySendsStatesToNotificationSocket(t *testing.T) {
+	socket := fakeNotifySocket(t)
+	if err := Notify(Ready, MainPID()); err != nil {
+		t.Fatalf("Failed to notify: %s", err)
+	}
+	expect := fmt.Sprintf("READY=1\nMAINPID=%d", os.Getpid())
+	if m := receive(t, socket); m != expect {
+		t.Errorf("Notified '%s', expected '%s'", m, expect)
+	}
+}
+
+func TestNotifyDoesNothingWithoutNotificationSocket(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	if err := Notify(Ready); err != nil {
+		t.Errorf("Failed to notify without socket: %s", err)
+	}
+}
+
+func TestWatchdogNotifiedAtHalfTheInterval(t *testing.T) {
+	socket := fakeNotifySocket(t)
+	t.Setenv("WATCHDOG_USEC", "100000")
+	t.Setenv("WATCHDOG_PID", fmt.Sprint(os.Getpid()))
+	stop := make(chan struct{})
+	defer close(stop)
+	go RunWatchdog(stop, log.Nop())
+	if err := socket.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("Failed to set deadline: %s", err)
+	}
+	if m := receive(t, socket); m != Watchdog {
+		t.Errorf("Notified '%s', expected '%s'", m, Watchdog)
+	}
+}
+
+func TestWatchdogNotEnabledForOtherProcess(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "100000")
+	t.Setenv("WATCHDOG_PID", "1")
+	if _, ok := WatchdogInterval(log.Nop()); ok {
+		t.Errorf("Watchdog enabled for process 1")
+	}
+}
+
+// fakeNotifySocket listens on a notification socket which
+// the process is configured to notify for the duration of the test
+func fakeNotifySocket(t *testing.T) *net.UnixConn {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	c, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("Failed to listen on notification socket: %s", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	t.Setenv("NOTIFY_SOCKET", path)
+	return c
+}
+
+// receive reads one message from the socket
+func receive(t *testing.T, c *net.UnixConn) string {
+	b := make([]byte, 1024)
+	n, err := c.Read(b)
+	if err != nil {
+		t.Fatalf("Failed to read from notification socket: %s", err)
+	}
+	return string(b[:n])
+}
@@ -0,0 +1,66 @@
+package url
+
+import "net/url"
+
+// QueryRewriter maps the query parameters of the incoming request
+// to the query parameters of the outgoing request
+type QueryRewriter func(url.Values) url.Values
+
+// RewriteQuery applies each of the query rewriters in order to the query parameters of
+// the URL. With no rewriters, the query string is left exactly as it was received.
+func RewriteQuery(u url.URL, qrs []QueryRewriter) url.URL {
+	if len(qrs) == 0 {
+		return u
+	}
+	v := u.Query()
+	for _, qr := range qrs {
+		v = qr(v)
+	}
+	u.RawQuery = v.Encode()
+	return u
+}
+
+// SetQueryParameter sets the named parameter to the value, replacing any existing values
+func SetQueryParameter(name string, value string) QueryRewriter {
+	return func(v url.Values) url.Values {
+		v.Set(name, value)
+		return v
+	}
+}
+
+// AddQueryParameter adds the value to any existing values of the named parameter
+func AddQueryParameter(name string, value string) QueryRewriter {
+	return func(v url.Values) url.Values {
+		v.Add(name, value)
+		return v
+	}
+}
+
+// RemoveQueryParameter removes all values of the named parameter
+func RemoveQueryParameter(name string) QueryRewriter {
+	return func(v url.Values) url.Values {
+		v.Del(name)
+		return v
+	}
+}
+
+// RenameQueryParameter moves all values of the named parameter
+// to the end of the values of the parameter named by to
+func RenameQueryParameter(name string, to string) QueryRewriter {
+	return func(v url.Values) url.Values {
+		vs, ok := v[name]
+		if !ok {
+			return v
+		}
+		v.Del(name)
+		v[to] = append(v[to], vs...)
+		return v
+	}
+}
+
+// DropQuery removes all query parameters
+func DropQuery() QueryRewriter {
+	return func(url.Values) url.Values {
+		return url.Values{}
+	}
+}
@@ -0,0 +1,69 @@
+package url
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestRetainsOriginalQueryStringExactlyWithNoQueryRewriters(t *testing.T) {
+	query := "oof=rab&foo=bar&oof=ferp"
+	u := url.URL{Host: "something-else:1089", Path: "/foo/", RawQuery: query}
+	r := RewriteQuery(u, []QueryRewriter{})
+	if r.RawQuery != query {
+		t.Errorf("Rewrote query to '%s' not '%s' from '%#v'", r.RawQuery, query, u)
+	}
+}
+
+func TestSetQueryParameterReplacesExistingValues(t *testing.T) {
+	u := url.URL{Host: "something-else:1089", Path: "/foo/", RawQuery: "key=one&key=two&foo=bar"}
+	r := RewriteQuery(u, []QueryRewriter{SetQueryParameter("key", "secret")})
+	expect := "foo=bar&key=secret"
+	if r.RawQuery != expect {
+		t.Errorf("Rewrote query to '%s' not '%s' from '%#v'", r.RawQuery, expect, u)
+	}
+}
+
+func TestAddQueryParameterRetainsExistingValues(t *testing.T) {
+	u := url.URL{Host: "something-else:1089", Path: "/foo/", RawQuery: "key=one"}
+	r := RewriteQuery(u, []QueryRewriter{AddQueryParameter("key", "two")})
+	expect := "key=one&key=two"
+	if r.RawQuery != expect {
+		t.Errorf("Rewrote query to '%s' not '%s' from '%#v'", r.RawQuery, expect, u)
+	}
+}
+
+func TestRemoveQueryParameterRemovesOnlyThatParameter(t *testing.T) {
+	u := url.URL{Host: "something-else:1089", Path: "/foo/", RawQuery: "key=one&key=two&foo=bar"}
+	r := RewriteQuery(u, []QueryRewriter{RemoveQueryParameter("key")})
+	expect := "foo=bar"
+	if r.RawQuery != expect {
+		t.Errorf("Rewrote query to '%s' not '%s' from '%#v'", r.RawQuery, expect, u)
+	}
+}
+
+func TestRenameQueryParameterMovesAllValues(t *testing.T) {
+	u := url.URL{Host: "something-else:1089", Path: "/foo/", RawQuery: "q=one&q=two&search=zero"}
+	r := RewriteQuery(u, []QueryRewriter{RenameQueryParameter("q", "search")})
+	expect := "search=zero&search=one&search=two"
+	if r.RawQuery != expect {
+		t.Errorf("Rewrote query to '%s' not '%s' from '%#v'", r.RawQuery, expect, u)
+	}
+}
+
+func TestRenameQueryParameterDoesNothingWhenParameterAbsent(t *testing.T) {
+	u := url.URL{Host: "something-else:1089", Path: "/foo/", RawQuery: "foo=bar"}
+	r := RewriteQuery(u, []QueryRewriter{RenameQueryParameter("q", "search")})
+	expect := "foo=bar"
+	if r.RawQuery != expect {
+		t.Errorf("Rewrote query to '%s' not '%s' from '%#v'", r.RawQuery, expect, u)
+	}
+}
+
+func TestDropQueryRemovesAllParametersAndLaterRewritersStillApply(t *testing.T) {
+	u := url.URL{Host: "something-else:1089", Path: "/foo/", RawQuery: "foo=bar&baz=rab"}
+	r := RewriteQuery(u, []QueryRewriter{DropQuery(), SetQueryParameter("key", "secret")})
+	expect := "key=secret"
+	if r.RawQuery != expect {
+		t.Errorf("Rewrote query to '%s' not '%s' from '%#v'", r.RawQuery, expect, u)
+	}
+}
@@ -9,17 +9,22 @@ import (
 
 // Rewrite adapts the provided URL to the new target,
 // mapping the original path to the new one and joining
-// this with the base path, taking care of e.g. repeated slashes
-func Rewrite(u url.URL, target BaseURL, pm PathRewriter) string {
+// this with the base path, taking care of e.g. repeated slashes.
+// Returns false if the path rewriter could not map the path.
+func Rewrite(u url.URL, target BaseURL, pm PathRewriter) (string, bool) {
 	u.Scheme = target.Protocol
 	u.Host = fmt.Sprintf("%s:%d", target.Host, target.Port)
-	mapped := pm(u.Path)
+	mapped, ok := pm(u.Path)
+	if !ok {
+		return "", false
+	}
 	u.Path = assembleFullPath(target.Path, mapped)
-	return u.String()
+	return u.String(), true
 }
 
-// PathRewriter maps between the incoming path and the outgoing path
-type PathRewriter func(string) string
+// PathRewriter maps between the incoming path and the outgoing path,
+// returning false if the incoming path cannot be mapped
+type PathRewriter func(string) (string, bool)
 
 // assembleFullPath combines the base and suffix into a single path
 // ensuring they are joined by a single slash
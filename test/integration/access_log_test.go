@@ -0,0 +1,71 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/snasphysicist/ferp/v2/pkg/configuration"
+)
+
+func TestAccessLogRecordsForwardedRequests(t *testing.T) {
+	m := mock{t: t, port: mockPorts()[0], routes: []route{
+		{path: "/test", method: http.MethodGet, rg: setResponse(http.StatusOK, "logged")},
+	}}
+
+	port := randomPort()
+	output := filepath.Join(t.TempDir(), "access.log")
+	all := 1.0
+	f := startMocksAndProxyConfigured(t, []mock{m}, func(c *configuration.Configuration) {
+		serverNamed(t, c, configuration.ServerHTTP).Port = port
+		c.AccessLog = configuration.AccessLog{Format: configuration.AccessLogJSON, Output: output, Sample: &all}
+	})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{method: http.MethodGet, url: fmt.Sprintf("http://127.0.0.1:%d/test", port), body: http.NoBody},
+		res: response{code: http.StatusOK, content: stringMatch{expect: "logged"}, headers: checkNoHeaders{}},
+	})
+
+	b := waitForAccessLog(t, output)
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	var e map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &e); err != nil {
+		t.Fatalf("Failed to parse access log line from %s: %s", b, err)
+	}
+	for k, v := range map[string]interface{}{
+		"method":    "GET",
+		"uri":       "/test",
+		"route":     "/test",
+		"target":    "test-1",
+		"upstream":  fmt.Sprintf("127.0.0.1:%d", mockPorts()[0]),
+		"status":    float64(http.StatusOK),
+		"bytes":     float64(len("logged")),
+		"client_ip": "127.0.0.1",
+	} {
+		if e[k] != v {
+			t.Errorf("Access log has %s %#v, expected %#v in %s", k, e[k], v, b)
+		}
+	}
+}
+
+// waitForAccessLog waits for the access log, which is written asynchronously,
+// to contain at least one line, returning its content
+func waitForAccessLog(t *testing.T, output string) []byte {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		b, err := os.ReadFile(output)
+		if err == nil && strings.Contains(string(b), "\n") {
+			return b
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Access log %s had no complete line in time, last read %q with error %v", output, b, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
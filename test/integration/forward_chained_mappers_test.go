@@ -0,0 +1,29 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestForwardsWithPathMappedByEachMapperInChain(t *testing.T) {
+	content := "Reached a chained test route"
+	m := mock{t: t, port: mockPorts()[0], routes: []route{
+		{path: "/v2/test", method: http.MethodGet, rg: setResponse(200, content)},
+	}}
+
+	p, f := startMocksAndProxy(t, []mock{m})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodGet,
+			url:    proxyURL(p, "chained/test"),
+			body:   http.NoBody,
+		},
+		res: response{
+			code:    http.StatusOK,
+			content: stringMatch{expect: content},
+			headers: checkNoHeaders{},
+		},
+	})
+}
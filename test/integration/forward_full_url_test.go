@@ -35,3 +35,34 @@ func TestForwardsQueryParametersOnDownstreamRequest(t *testing.T) {
 		},
 	})
 }
+
+func TestRewritesQueryParametersOnDownstreamRequestWhenConfigured(t *testing.T) {
+	m := mock{t: t, port: mockPorts()[0], routes: []route{
+		{path: "/query/test", method: http.MethodGet, rg: echoQueryParameters()},
+	}}
+
+	p, f := startMocksAndProxy(t, []mock{m})
+	defer f()
+
+	expect, err := json.Marshal(map[string][]string{
+		"foo":    {"bar"},
+		"key":    {"secret"},
+		"search": {"rab", "ferp"},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodGet,
+			url:    proxyURL(p, "query/test") + "?foo=bar&key=mine&debug=true&q=rab&q=ferp",
+			body:   http.NoBody,
+		},
+		res: response{
+			code:    http.StatusOK,
+			content: stringMatch{expect: string(expect)},
+			headers: checkNoHeaders{},
+		},
+	})
+}
@@ -114,3 +114,88 @@ func (m ensureDoesNotContainJSONSerialisedHeaders) Check(t *testing.T, b []byte)
 		}
 	}
 }
+
+func TestAppliesConfiguredHeaderRulesToDownstreamRequest(t *testing.T) {
+	m := mock{t: t, port: mockPorts()[0], routes: []route{
+		{path: "/headers/test", method: http.MethodGet, rg: echoHeaders()},
+	}}
+
+	p, f := startMocksAndProxy(t, []mock{m})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodGet,
+			url:    proxyURL(p, "headers/test"),
+			body:   http.NoBody,
+			headers: http.Header{
+				"Cookie": []string{"session=secret"},
+				"X-Old":  []string{"foo"},
+			},
+		},
+		res: response{
+			code: http.StatusOK,
+			content: ensureContainsJSONSerialisedHeaders{expect: http.Header{
+				"X-Route": []string{"route=/headers/test"},
+				"X-New":   []string{"foo"},
+			}},
+			headers: checkNoHeaders{},
+		},
+	})
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodGet,
+			url:    proxyURL(p, "headers/test"),
+			body:   http.NoBody,
+			headers: http.Header{
+				"Cookie": []string{"session=secret"},
+				"X-Old":  []string{"foo"},
+			},
+		},
+		res: response{
+			code: http.StatusOK,
+			content: ensureDoesNotContainJSONSerialisedHeaders{expect: http.Header{
+				"Cookie": []string{},
+				"X-Old":  []string{},
+			}},
+			headers: checkNoHeaders{},
+		},
+	})
+}
+
+func TestAppliesConfiguredHeaderRulesToResponse(t *testing.T) {
+	m := mock{t: t, port: mockPorts()[0], routes: []route{
+		{path: "/headers/test", method: http.MethodGet, rg: setResponse(200, "")},
+	}}
+
+	p, f := startMocksAndProxy(t, []mock{m})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodGet,
+			url:    proxyURL(p, "headers/test"),
+			body:   http.NoBody,
+		},
+		res: response{
+			code:    http.StatusOK,
+			content: stringMatch{expect: ""},
+			headers: checkHeader{name: "Strict-Transport-Security", expect: "max-age=63072000"},
+		},
+	})
+}
+
+// checkHeader is a headerMatcher which expects the named header
+// to contain precisely one value, which matches exactly the expected string
+type checkHeader struct {
+	name   string
+	expect string
+}
+
+// Check implements headerMatcher for checkHeader, see struct for behaviour
+func (c checkHeader) Check(t *testing.T, h http.Header) {
+	vs := h.Values(c.name)
+	if !reflect.DeepEqual(vs, []string{c.expect}) {
+		t.Errorf("%s header values %#v, expected one value '%s'", c.name, vs, c.expect)
+	}
+}
@@ -0,0 +1,73 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestSendsDownstreamAddressAsHostByDefault(t *testing.T) {
+	m := mock{t: t, port: mockPorts()[0], routes: []route{
+		{path: "/host/downstream", method: http.MethodGet, rg: echoHost()},
+	}}
+
+	p, f := startMocksAndProxy(t, []mock{m})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodGet,
+			url:    proxyURL(p, "host/downstream"),
+			body:   http.NoBody,
+		},
+		res: response{
+			code:    http.StatusOK,
+			content: stringMatch{expect: fmt.Sprintf("127.0.0.1:%d", mockPorts()[0])},
+			headers: checkNoHeaders{},
+		},
+	})
+}
+
+func TestSendsClientHostToDownstreamWhenConfiguredToPreserve(t *testing.T) {
+	m := mock{t: t, port: mockPorts()[0], routes: []route{
+		{path: "/host/preserve", method: http.MethodGet, rg: echoHost()},
+	}}
+
+	p, f := startMocksAndProxy(t, []mock{m})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodGet,
+			url:    proxyURL(p, "host/preserve"),
+			body:   http.NoBody,
+		},
+		res: response{
+			code:    http.StatusOK,
+			content: stringMatch{expect: fmt.Sprintf("localhost:%d", p)},
+			headers: checkNoHeaders{},
+		},
+	})
+}
+
+func TestSendsFixedHostToDownstreamWhenConfigured(t *testing.T) {
+	m := mock{t: t, port: mockPorts()[0], routes: []route{
+		{path: "/host/fixed", method: http.MethodGet, rg: echoHost()},
+	}}
+
+	p, f := startMocksAndProxy(t, []mock{m})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodGet,
+			url:    proxyURL(p, "host/fixed"),
+			body:   http.NoBody,
+		},
+		res: response{
+			code:    http.StatusOK,
+			content: stringMatch{expect: "www.example.com"},
+			headers: checkNoHeaders{},
+		},
+	})
+}
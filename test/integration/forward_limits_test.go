@@ -0,0 +1,151 @@
+package integration
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/snasphysicist/ferp/v2/pkg/configuration"
+)
+
+func TestForwardsBodyWithinLimit(t *testing.T) {
+	m := mock{t: t, port: mockPorts()[0], routes: []route{
+		{path: "/limited/upload", method: http.MethodPost, rg: echoBody()},
+	}}
+
+	p, f := startMocksAndProxy(t, []mock{m})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodPost,
+			url:    proxyURL(p, "limited/upload"),
+			body:   io.NopCloser(strings.NewReader("small")),
+		},
+		res: response{
+			code:    http.StatusOK,
+			content: stringMatch{expect: "small"},
+			headers: checkNoHeaders{},
+		},
+	})
+}
+
+func TestRejectsBodyLargerThanLimitWithConfiguredMessage(t *testing.T) {
+	m := mock{t: t, port: mockPorts()[0], routes: []route{
+		{path: "/limited/upload", method: http.MethodPost, rg: echoBody()},
+	}}
+
+	p, f := startMocksAndProxy(t, []mock{m})
+	defer f()
+
+	// with a known length, rejected before forwarding
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodPost,
+			url:    proxyURL(p, "limited/upload"),
+			body:   io.NopCloser(strings.NewReader(strings.Repeat("x", 32))),
+			length: 32,
+		},
+		res: response{
+			code:    http.StatusRequestEntityTooLarge,
+			content: stringMatch{expect: "too big"},
+			headers: checkNoHeaders{},
+		},
+	})
+	// without a known length (chunked), rejected on reading past the limit
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodPost,
+			url:    proxyURL(p, "limited/upload"),
+			body:   io.NopCloser(strings.NewReader(strings.Repeat("x", 32))),
+		},
+		res: response{
+			code:    http.StatusRequestEntityTooLarge,
+			content: stringMatch{expect: "too big"},
+			headers: checkNoHeaders{},
+		},
+	})
+}
+
+func TestRejectsRequestWithTooManyHeaders(t *testing.T) {
+	m := mock{t: t, port: mockPorts()[0], routes: []route{
+		{path: "/test", method: http.MethodGet, rg: setResponse(http.StatusOK, "")},
+	}}
+
+	p, f := startMocksAndProxy(t, []mock{m})
+	defer f()
+
+	h := make(http.Header)
+	for i := 0; i < 51; i++ {
+		h.Add(fmt.Sprintf("X-Header-%d", i), "value")
+	}
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method:  http.MethodGet,
+			url:     proxyURL(p, "test"),
+			body:    http.NoBody,
+			headers: h,
+		},
+		res: response{
+			code:    http.StatusRequestHeaderFieldsTooLarge,
+			content: stringMatch{expect: "431: too many headers"},
+			headers: checkNoHeaders{},
+		},
+	})
+}
+
+func TestRespondsGatewayTimeoutIfDownstreamDoesNotRespondWithinMaxDuration(t *testing.T) {
+	m := mock{t: t, port: mockPorts()[0], routes: []route{
+		{path: "/test", method: http.MethodGet, rg: delayedResponse(500*time.Millisecond, http.StatusOK, "too late")},
+	}}
+
+	port := randomPort()
+	f := startMocksAndProxyConfigured(t, []mock{m}, func(c *configuration.Configuration) {
+		s := serverNamed(t, c, configuration.ServerHTTP)
+		s.Port = port
+		s.Incoming[incomingIndex(t, s, "/test")].Limits.MaxDuration = 100 * time.Millisecond
+	})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{method: http.MethodGet, url: proxyURL(port, "test"), body: http.NoBody},
+		res: response{
+			code:    http.StatusGatewayTimeout,
+			content: stringMatch{expect: "504: downstream took too long to respond"},
+			headers: checkNoHeaders{},
+		},
+	})
+}
+
+func TestStreamsResponseBodyForLongerThanMaxDuration(t *testing.T) {
+	m := mock{t: t, port: mockPorts()[0], routes: []route{
+		{path: "/test", method: http.MethodGet, rg: slowlyStreamedResponse(100*time.Millisecond, "a", "b", "c")},
+	}}
+
+	port := randomPort()
+	f := startMocksAndProxyConfigured(t, []mock{m}, func(c *configuration.Configuration) {
+		s := serverNamed(t, c, configuration.ServerHTTP)
+		s.Port = port
+		s.Incoming[incomingIndex(t, s, "/test")].Limits.MaxDuration = 150 * time.Millisecond
+	})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{method: http.MethodGet, url: proxyURL(port, "test"), body: http.NoBody},
+		res: response{code: http.StatusOK, content: stringMatch{expect: "abc"}, headers: checkNoHeaders{}},
+	})
+}
+
+// incomingIndex finds the index of the incoming with the path in the server, failing the test if there is none
+func incomingIndex(t *testing.T, s *configuration.Server, path string) int {
+	for i, in := range s.Incoming {
+		if in.Path == path {
+			return i
+		}
+	}
+	t.Fatalf("No incoming with path %s in %+v", path, s.Incoming)
+	return 0
+}
@@ -0,0 +1,52 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestForwardsWithPrefixReplacedWhenConfigured(t *testing.T) {
+	content := "Reached a replaced prefix test route"
+	m := mock{t: t, port: mockPorts()[0], routes: []route{
+		{path: "/v2/test", method: http.MethodGet, rg: setResponse(200, content)},
+	}}
+
+	p, f := startMocksAndProxy(t, []mock{m})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodGet,
+			url:    proxyURL(p, "v1/test"),
+			body:   http.NoBody,
+		},
+		res: response{
+			code:    http.StatusOK,
+			content: stringMatch{expect: content},
+			headers: checkNoHeaders{},
+		},
+	})
+}
+
+func TestDoesNotForwardPathWithoutPrefixWhenStrict(t *testing.T) {
+	content := "Reached a replaced prefix test route"
+	m := mock{t: t, port: mockPorts()[0], routes: []route{
+		{path: "/legacy/test", method: http.MethodGet, rg: setResponse(200, content)},
+	}}
+
+	p, f := startMocksAndProxy(t, []mock{m})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodGet,
+			url:    proxyURL(p, "legacy/test"),
+			body:   http.NoBody,
+		},
+		res: response{
+			code:    http.StatusNotFound,
+			content: stringMatch{expect: "404 page not found\n"},
+			headers: checkNoHeaders{},
+		},
+	})
+}
@@ -0,0 +1,89 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestRewritesLocationAndCookiesFromDownstreamBackToProxy(t *testing.T) {
+	m := mock{t: t, port: mockPorts()[0], routes: []route{
+		{path: "/app/test", method: http.MethodGet, rg: redirectToLoginWithCookie()},
+	}}
+
+	p, f := startMocksAndProxy(t, []mock{m})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodGet,
+			url:    proxyURL(p, "rewritten/test"),
+			body:   http.NoBody,
+		},
+		res: response{
+			code:    http.StatusFound,
+			content: stringMatch{expect: ""},
+			headers: checkAllHeaders{
+				checkHeader{name: "Location", expect: fmt.Sprintf("http://localhost:%d/rewritten/login?next=1", p)},
+				checkHeader{name: "Content-Location", expect: "/rewritten/test"},
+				checkHeader{name: "Set-Cookie", expect: "session=abc; Path=/rewritten/; HttpOnly"},
+			},
+		},
+	})
+}
+
+func TestFollowsRedirectsAndDoesNotRewriteResponsesUnlessConfigured(t *testing.T) {
+	m := mock{t: t, port: mockPorts()[0], routes: []route{
+		{path: "/test", method: http.MethodGet, rg: redirectToLoginWithCookie()},
+		{path: "/app/login", method: http.MethodGet, rg: loginWithCookie()},
+	}}
+
+	p, f := startMocksAndProxy(t, []mock{m})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodGet,
+			url:    proxyURL(p, "test"),
+			body:   http.NoBody,
+		},
+		res: response{
+			code:    http.StatusOK,
+			content: stringMatch{expect: "login"},
+			headers: checkAllHeaders{
+				checkHeader{name: "Set-Cookie", expect: "session=abc; Path=/app/; HttpOnly"},
+			},
+		},
+	})
+}
+
+// redirectToLoginWithCookie generates a redirect to an absolute URL on the mock
+// under /app, which also sets a cookie for that path
+func redirectToLoginWithCookie() responseGenerator {
+	return func(r *http.Request) responseSpecification {
+		return responseSpecification{status: http.StatusFound, body: "", headers: http.Header{
+			"Location":         []string{fmt.Sprintf("http://127.0.0.1:%d/app/login?next=1", mockPorts()[0])},
+			"Content-Location": []string{r.URL.Path},
+			"Set-Cookie":       []string{"session=abc; Path=/app/; HttpOnly"},
+		}}
+	}
+}
+
+// loginWithCookie generates a login page, which also sets a cookie for the path /app
+func loginWithCookie() responseGenerator {
+	return func(*http.Request) responseSpecification {
+		return responseSpecification{status: http.StatusOK, body: "login", headers: http.Header{
+			"Set-Cookie": []string{"session=abc; Path=/app/; HttpOnly"},
+		}}
+	}
+}
+
+// checkAllHeaders is a headerMatcher which runs all the wrapped headerMatchers
+type checkAllHeaders []headerMatcher
+
+// Check implements headerMatcher for checkAllHeaders, see type for behaviour
+func (c checkAllHeaders) Check(t *testing.T, h http.Header) {
+	for _, m := range c {
+		m.Check(t, h)
+	}
+}
@@ -0,0 +1,82 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/snasphysicist/ferp/v2/pkg/configuration"
+)
+
+func TestServesOnAllListenAddressesIncludingUnixSockets(t *testing.T) {
+	m := mock{t: t, port: mockPorts()[0], routes: []route{
+		{path: "/test", method: http.MethodGet, rg: setResponse(http.StatusOK, "listening")},
+	}}
+
+	port := randomPort()
+	socket := filepath.Join(t.TempDir(), "ferp.sock")
+	f := startMocksAndProxyConfigured(t, []mock{m}, func(c *configuration.Configuration) {
+		serverNamed(t, c, configuration.ServerHTTP).Listen = []configuration.Listen{
+			{Address: fmt.Sprintf("127.0.0.1:%d", port)},
+			{Address: configuration.UnixPrefix + socket, Permissions: 0600},
+		}
+	})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodGet,
+			url:    fmt.Sprintf("http://127.0.0.1:%d/test", port),
+			body:   http.NoBody,
+		},
+		res: response{
+			code:    http.StatusOK,
+			content: stringMatch{expect: "listening"},
+			headers: checkNoHeaders{},
+		},
+	})
+
+	c := http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _ string, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	res, err := c.Get("http://ferp/test")
+	if err != nil {
+		t.Fatalf("Failed to send request over unix socket: %s", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+	b, err := io.ReadAll(res.Body)
+	if err != nil || string(b) != "listening" {
+		t.Errorf("Response over unix socket '%s' (error %v), expected 'listening'", b, err)
+	}
+
+	info, err := os.Stat(socket)
+	if err != nil {
+		t.Fatalf("Failed to stat unix socket: %s", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Unix socket has permissions %o, expected 600", info.Mode().Perm())
+	}
+}
+
+func TestDoesNotListenOnPortWhenListenAddressesConfigured(t *testing.T) {
+	port := randomPort()
+	f := startMocksAndProxyConfigured(t, []mock{}, func(c *configuration.Configuration) {
+		s := serverNamed(t, c, configuration.ServerHTTP)
+		s.Port = port
+		s.Listen = []configuration.Listen{{Address: fmt.Sprintf("127.0.0.1:%d", randomPort())}}
+	})
+	defer f()
+
+	time.Sleep(50 * time.Millisecond)
+	if _, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/test", port)); err == nil {
+		t.Errorf("Proxy responded on port %d, but should only listen on the configured addresses", port)
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -30,9 +32,11 @@ type route struct {
 type responseGenerator func(r *http.Request) responseSpecification
 
 type responseSpecification struct {
-	status  int
-	body    string
-	headers http.Header
+	status     int
+	body       string
+	headers    http.Header
+	chunks     []string // written after the body, each after flushing and waiting for chunkDelay
+	chunkDelay time.Duration
 }
 
 // start starts the mock server in a new goroutine, and returns a function
@@ -79,6 +83,15 @@ func (m mock) handler(rg responseGenerator) http.HandlerFunc {
 		if err != nil {
 			m.t.Errorf("Failed to write response content: %s", err)
 		}
+		for _, c := range rs.chunks {
+			if err := http.NewResponseController(w).Flush(); err != nil {
+				m.t.Errorf("Failed to flush response: %s", err)
+			}
+			time.Sleep(rs.chunkDelay)
+			if _, err := w.Write([]byte(c)); err != nil {
+				m.t.Errorf("Failed to write response chunk: %s", err)
+			}
+		}
 	}
 }
 
@@ -117,3 +130,41 @@ func echoHeaders() responseGenerator {
 		return responseSpecification{status: 200, body: string(b), headers: make(http.Header)}
 	}
 }
+
+// echoHost returns a 200 and writes the host
+// to which the request was sent into the body
+func echoHost() responseGenerator {
+	return func(r *http.Request) responseSpecification {
+		return responseSpecification{status: 200, body: r.Host, headers: make(http.Header)}
+	}
+}
+
+// echoBody returns a 200 and writes the body of the request into the body
+func echoBody() responseGenerator {
+	return func(r *http.Request) responseSpecification {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			return responseSpecification{status: 400, body: err.Error(), headers: make(http.Header)}
+		}
+		return responseSpecification{status: 200, body: string(b), headers: make(http.Header)}
+	}
+}
+
+// slowlyStreamedResponse returns a 200 with the chunks as the body, the first immediately,
+// and each of the rest after waiting for the delay
+func slowlyStreamedResponse(delay time.Duration, chunks ...string) responseGenerator {
+	return func(*http.Request) responseSpecification {
+		return responseSpecification{
+			status: http.StatusOK, body: chunks[0], headers: make(http.Header),
+			chunks: chunks[1:], chunkDelay: delay,
+		}
+	}
+}
+
+// delayedResponse waits for the delay, then returns the provided code and content for the response
+func delayedResponse(delay time.Duration, code int, content string) responseGenerator {
+	return func(*http.Request) responseSpecification {
+		time.Sleep(delay)
+		return responseSpecification{status: code, body: content, headers: make(http.Header)}
+	}
+}
@@ -0,0 +1,60 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRedirectsPathFoundInRedirectMapWithDefaultStatus(t *testing.T) {
+	p, f := startMocksAndProxy(t, []mock{})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodGet,
+			url:    proxyURL(p, "old/about.html"),
+			body:   http.NoBody,
+		},
+		res: response{
+			code:    http.StatusFound,
+			content: checkNothing{},
+			headers: checkLocationHeader{content: "/about"},
+		},
+	})
+}
+
+func TestRedirectsPathFoundInRedirectMapWithEntryStatus(t *testing.T) {
+	p, f := startMocksAndProxy(t, []mock{})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodGet,
+			url:    proxyURL(p, "old/contact.html"),
+			body:   http.NoBody,
+		},
+		res: response{
+			code:    http.StatusMovedPermanently,
+			content: checkNothing{},
+			headers: checkLocationHeader{content: "/contact"},
+		},
+	})
+}
+
+func TestDoesNotRedirectPathFoundInRedirectMapForNonGetMethod(t *testing.T) {
+	p, f := startMocksAndProxy(t, []mock{})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodPost,
+			url:    proxyURL(p, "old/about.html"),
+			body:   http.NoBody,
+		},
+		res: response{
+			code:    http.StatusNotFound,
+			content: checkNothing{},
+			headers: checkNoHeaders{},
+		},
+	})
+}
@@ -41,6 +41,168 @@ func TestConfiguredRedirectReturnsLocationHeaderWithTargetURL(t *testing.T) {
 	})
 }
 
+func TestConfiguredRedirectDropsQueryStringByDefault(t *testing.T) {
+	p, f := startMocksAndProxy(t, []mock{})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodGet,
+			url:    proxyURL(p, "redirect-me") + "?oof=rab",
+			body:   http.NoBody,
+		},
+		res: response{
+			code:    http.StatusFound,
+			content: checkNothing{},
+			headers: checkLocationHeader{content: "/you-are-redirected"},
+		},
+	})
+}
+
+func TestPermanentRedirectReturnsConfiguredStatusAndCacheControl(t *testing.T) {
+	p, f := startMocksAndProxy(t, []mock{})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodGet,
+			url:    proxyURL(p, "redirect-me-permanently"),
+			body:   http.NoBody,
+		},
+		res: response{
+			code:    http.StatusMovedPermanently,
+			content: checkNothing{},
+			headers: checkCacheControlHeaderPresent{},
+		},
+	})
+}
+
+func TestRedirectConfiguredToPreserveQueryReplacesTargetQuery(t *testing.T) {
+	p, f := startMocksAndProxy(t, []mock{})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodGet,
+			url:    proxyURL(p, "redirect-me-preserving-query") + "?oof=rab",
+			body:   http.NoBody,
+		},
+		res: response{
+			code:    http.StatusTemporaryRedirect,
+			content: checkNothing{},
+			headers: checkLocationHeader{content: "/you-are-redirected?oof=rab"},
+		},
+	})
+}
+
+func TestRedirectConfiguredToMergeQueryCombinesTargetAndIncomingQueries(t *testing.T) {
+	p, f := startMocksAndProxy(t, []mock{})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodGet,
+			url:    proxyURL(p, "redirect-me-merging-query") + "?oof=rab&foo=baz",
+			body:   http.NoBody,
+		},
+		res: response{
+			code:    http.StatusFound,
+			content: checkNothing{},
+			headers: checkLocationHeader{content: "/you-are-redirected?foo=bar&foo=baz&oof=rab"},
+		},
+	})
+}
+
+func TestPatternRedirectFillsTargetWithCapturedParameters(t *testing.T) {
+	p, f := startMocksAndProxy(t, []mock{})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodGet,
+			url:    proxyURL(p, "blog/2022/hello-world"),
+			body:   http.NoBody,
+		},
+		res: response{
+			code:    http.StatusFound,
+			content: checkNothing{},
+			headers: checkLocationHeader{content: "/posts/hello-world?year=2022"},
+		},
+	})
+}
+
+func TestPatternRedirectDoesNotMatchWhenCaptureRegexDoesNotMatch(t *testing.T) {
+	p, f := startMocksAndProxy(t, []mock{})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodGet,
+			url:    proxyURL(p, "blog/recent/hello-world"),
+			body:   http.NoBody,
+		},
+		res: response{
+			code:    http.StatusNotFound,
+			content: checkNothing{},
+			headers: checkNoHeaders{},
+		},
+	})
+}
+
+func TestPatternRedirectFillsTargetWithCapturedWildcard(t *testing.T) {
+	p, f := startMocksAndProxy(t, []mock{})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodGet,
+			url:    proxyURL(p, "old-files/some/nested/file.txt"),
+			body:   http.NoBody,
+		},
+		res: response{
+			code:    http.StatusFound,
+			content: checkNothing{},
+			headers: checkLocationHeader{content: "/files/some/nested/file.txt"},
+		},
+	})
+}
+
+func TestPatternRedirectFillsTargetWithNamedRegexpGroups(t *testing.T) {
+	p, f := startMocksAndProxy(t, []mock{})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodGet,
+			url:    proxyURL(p, "archive/2022-07"),
+			body:   http.NoBody,
+		},
+		res: response{
+			code:    http.StatusFound,
+			content: checkNothing{},
+			headers: checkLocationHeader{content: "/posts/2022/07"},
+		},
+	})
+}
+
+func TestPatternRedirectQueryEscapesCapturesInTargetQuery(t *testing.T) {
+	p, f := startMocksAndProxy(t, []mock{})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{
+			method: http.MethodGet,
+			url:    proxyURL(p, "search/a&admin=1"),
+			body:   http.NoBody,
+		},
+		res: response{
+			code:    http.StatusFound,
+			content: checkNothing{},
+			headers: checkLocationHeader{content: "/find?q=a%26admin%3D1"},
+		},
+	})
+}
+
 // checkNothing is a contentMatcher that never fails the test
 type checkNothing struct{}
 
@@ -70,3 +232,14 @@ func (c checkLocationHeader) Check(t *testing.T, h http.Header) {
 			locations[0], c.content)
 	}
 }
+
+// checkCacheControlHeaderPresent is a headerMatcher which expects
+// the Cache-Control header to be set (to anything)
+type checkCacheControlHeaderPresent struct{}
+
+// Check implements headerMatcher for checkCacheControlHeaderPresent, see struct for behaviour
+func (checkCacheControlHeaderPresent) Check(t *testing.T, h http.Header) {
+	if h.Get("Cache-Control") == "" {
+		t.Errorf("No cache-control header in %#v", h)
+	}
+}
@@ -0,0 +1,59 @@
+package integration
+
+import (
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/snasphysicist/ferp/v2/pkg/configuration"
+)
+
+func TestRequestIDIsForwardedAndEchoed(t *testing.T) {
+	m := mock{t: t, port: mockPorts()[0], routes: []route{
+		{path: "/test", method: http.MethodGet, rg: echoCorrelationID()},
+	}}
+
+	port := randomPort()
+	f := startMocksAndProxyConfigured(t, []mock{m}, func(c *configuration.Configuration) {
+		serverNamed(t, c, configuration.ServerHTTP).Port = port
+		c.RequestID.Header = "X-Correlation-ID"
+	})
+	defer f()
+
+	for incoming, expectSame := range map[string]bool{
+		"abc-123":       true,
+		"":              false,
+		"not valid ID!": false,
+	} {
+		req, err := http.NewRequest(http.MethodGet, proxyURL(port, "test"), http.NoBody)
+		if err != nil {
+			t.Fatalf("Failed to construct request: %s", err)
+		}
+		if incoming != "" {
+			req.Header.Set("X-Correlation-ID", incoming)
+		}
+		res := doUntilResponse(req, 11, time.Millisecond)
+		b, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatalf("Failed to read response body: %s", err)
+		}
+		forwarded := string(b)
+		if expectSame != (forwarded == incoming) || forwarded == "" {
+			t.Errorf("Incoming ID '%s' was forwarded as '%s'", incoming, forwarded)
+		}
+		echoed := res.Header.Values("X-Correlation-ID")
+		if len(echoed) != 1 || echoed[0] != forwarded {
+			t.Errorf("Echoed IDs %v, expected only the forwarded '%s'", echoed, forwarded)
+		}
+	}
+}
+
+// echoCorrelationID returns a 200 and writes the X-Correlation-ID
+// header of the request into the body, and echoes it as a header
+func echoCorrelationID() responseGenerator {
+	return func(r *http.Request) responseSpecification {
+		id := r.Header.Get("X-Correlation-ID")
+		return responseSpecification{status: 200, body: id, headers: http.Header{"X-Correlation-ID": []string{id}}}
+	}
+}
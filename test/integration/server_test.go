@@ -0,0 +1,44 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/snasphysicist/ferp/v2/pkg/configuration"
+)
+
+func TestNamedServerServesOnlyItsOwnRoutes(t *testing.T) {
+	m := mock{t: t, port: mockPorts()[0], routes: []route{
+		{path: "/internal/test", method: http.MethodGet, rg: setResponse(http.StatusOK, "internal")},
+		{path: "/test", method: http.MethodGet, rg: setResponse(http.StatusOK, "public")},
+	}}
+
+	public := randomPort()
+	internal := randomPort()
+	f := startMocksAndProxyConfigured(t, []mock{m}, func(c *configuration.Configuration) {
+		serverNamed(t, c, configuration.ServerHTTP).Port = public
+		serverNamed(t, c, "internal").Port = internal
+	})
+	defer f()
+
+	for _, rr := range []requestResponse{
+		{
+			req: request{method: http.MethodGet, url: proxyURL(internal, "internal/test"), body: http.NoBody},
+			res: response{code: http.StatusOK, content: stringMatch{expect: "internal"}, headers: checkNoHeaders{}},
+		},
+		{
+			req: request{method: http.MethodGet, url: proxyURL(public, "test"), body: http.NoBody},
+			res: response{code: http.StatusOK, content: stringMatch{expect: "public"}, headers: checkNoHeaders{}},
+		},
+		{
+			req: request{method: http.MethodGet, url: proxyURL(public, "internal/test"), body: http.NoBody},
+			res: response{code: http.StatusNotFound, content: checkNothing{}, headers: checkNoHeaders{}},
+		},
+		{
+			req: request{method: http.MethodGet, url: proxyURL(internal, "test"), body: http.NoBody},
+			res: response{code: http.StatusNotFound, content: checkNothing{}, headers: checkNoHeaders{}},
+		},
+	} {
+		sendRequestExpectResponse(t, rr)
+	}
+}
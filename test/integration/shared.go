@@ -15,6 +15,7 @@ import (
 	"github.com/snasphysicist/ferp/v2/pkg/configuration"
 	"github.com/snasphysicist/ferp/v2/pkg/functional"
 	"github.com/snasphysicist/ferp/v2/pkg/log"
+	"github.com/snasphysicist/ferp/v2/pkg/mapper"
 )
 
 // mustFindFile searches recursively, starting from startIn and moving through its parents,
@@ -87,6 +88,7 @@ func sendRequestExpectResponse(t *testing.T, rr requestResponse) {
 		t.Errorf("Failed to construct request: %s", err)
 		return
 	}
+	req.ContentLength = rr.req.length
 	for h, vs := range rr.req.headers {
 		for _, v := range vs {
 			req.Header.Add(h, v)
@@ -118,6 +120,7 @@ type request struct {
 	url     string
 	body    io.ReadCloser
 	headers http.Header
+	length  int64 // if set, sent as the Content-Length, otherwise the body is sent chunked
 }
 
 // response represents the expected state of a response to be returned during a test
@@ -162,13 +165,40 @@ func (checkNoHeaders) Check(*testing.T, http.Header) {}
 // using the test configuration, bundling all shutdown/cleanup into
 // the returned function, which should be deferred from the test.
 func startMocksAndProxy(t *testing.T, mocks []mock) (uint16, func()) {
-	_, _ = log.Initialise()
-	c, err := configuration.Load(mustFindFile("test.yaml", "."))
+	port := randomPort()
+	f := startMocksAndProxyConfigured(t, mocks, func(c *configuration.Configuration) {
+		serverNamed(t, c, configuration.ServerHTTP).Port = port
+	})
+	return port, f
+}
+
+// serverNamed finds the server with the name in the configuration, failing the test if there is none
+func serverNamed(t *testing.T, c *configuration.Configuration, name string) *configuration.Server {
+	for i := range c.Servers {
+		if c.Servers[i].Name == name {
+			return &c.Servers[i]
+		}
+	}
+	t.Fatalf("No server named %s in %+v", name, c.Servers)
+	return nil
+}
+
+// startMocksAndProxyConfigured starts the provided mocks and the proxy server using
+// the test configuration with random ports, after changing it with configure, bundling all
+// shutdown/cleanup into the returned function, which should be deferred from the test.
+func startMocksAndProxyConfigured(
+	t *testing.T, mocks []mock, configure func(*configuration.Configuration),
+) func() {
+	c, err := configuration.Load(mustFindFile("test.yaml", "."), mapper.NewRegistry(), log.Nop())
 	if err != nil {
 		t.Errorf("Failed to load configuration: %s", err)
 	}
 
-	c.HTTP.Port = randomPort()
+	// servers left on fixed ports would clash with those of earlier tests still shutting down
+	for i := range c.Servers {
+		c.Servers[i].Port = randomPort()
+	}
+	configure(&c)
 
 	stop := make(chan struct{})
 	shutdowns := make([]func(), 0)
@@ -178,8 +208,8 @@ func startMocksAndProxy(t *testing.T, mocks []mock) (uint16, func()) {
 		shutdowns = append(shutdowns, m.start())
 	}
 
-	go command.Serve(c, stop)
-	return c.HTTP.Port, func() {
+	go command.Serve(c, log.Nop(), stop)
+	return func() {
 		for _, s := range shutdowns {
 			s()
 		}
@@ -0,0 +1,61 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/snasphysicist/ferp/v2/pkg/configuration"
+)
+
+func TestReadinessChecksFailDuringShutdownDelay(t *testing.T) {
+	port := randomPort()
+	f := startMocksAndProxyConfigured(t, []mock{}, func(c *configuration.Configuration) {
+		serverNamed(t, c, configuration.ServerHTTP).Port = port
+		c.Shutdown.Delay = 500 * time.Millisecond
+	})
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{method: http.MethodGet, url: proxyURL(port, "ready"), body: http.NoBody},
+		res: response{code: http.StatusOK, content: stringMatch{expect: "ready"}, headers: checkNoHeaders{}},
+	})
+	f()
+	time.Sleep(50 * time.Millisecond)
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{method: http.MethodGet, url: proxyURL(port, "ready"), body: http.NoBody},
+		res: response{code: http.StatusServiceUnavailable, content: stringMatch{expect: "stopping"}, headers: checkNoHeaders{}},
+	})
+}
+
+func TestInFlightRequestsCompleteDuringShutdown(t *testing.T) {
+	m := mock{t: t, port: mockPorts()[0], routes: []route{
+		{path: "/test", method: http.MethodGet, rg: slowResponse(300*time.Millisecond, "finished")},
+	}}
+
+	p, f := startMocksAndProxy(t, []mock{m})
+	// wait for the proxy to be ready before sending the slow request
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{method: http.MethodGet, url: proxyURL(p, "ready"), body: http.NoBody},
+		res: response{code: http.StatusOK, content: stringMatch{expect: "ready"}, headers: checkNoHeaders{}},
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sendRequestExpectResponse(t, requestResponse{
+			req: request{method: http.MethodGet, url: proxyURL(p, "test"), body: http.NoBody},
+			res: response{code: http.StatusOK, content: stringMatch{expect: "finished"}, headers: checkNoHeaders{}},
+		})
+	}()
+	time.Sleep(100 * time.Millisecond)
+	f()
+	<-done
+}
+
+// slowResponse waits for the delay before returning a 200 with the content
+func slowResponse(delay time.Duration, content string) responseGenerator {
+	return func(*http.Request) responseSpecification {
+		time.Sleep(delay)
+		return responseSpecification{status: http.StatusOK, body: content, headers: make(http.Header)}
+	}
+}
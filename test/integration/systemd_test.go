@@ -0,0 +1,41 @@
+package integration
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNotifiesSystemdWhenReadyAndStopping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	socket, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("Failed to listen on notification socket: %s", err)
+	}
+	defer func() { _ = socket.Close() }()
+	t.Setenv("NOTIFY_SOCKET", path)
+
+	_, f := startMocksAndProxy(t, []mock{})
+	expectNotification(t, socket, fmt.Sprintf("READY=1\nMAINPID=%d", os.Getpid()))
+	f()
+	expectNotification(t, socket, "STOPPING=1")
+}
+
+// expectNotification fails the test if the next message on the
+// notification socket is not the expected one, or none arrives
+func expectNotification(t *testing.T, socket *net.UnixConn, expect string) {
+	if err := socket.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatalf("Failed to set deadline: %s", err)
+	}
+	b := make([]byte, 1024)
+	n, err := socket.Read(b)
+	if err != nil {
+		t.Fatalf("No notification '%s' received: %s", expect, err)
+	}
+	if string(b[:n]) != expect {
+		t.Errorf("Received notification '%s', expected '%s'", b[:n], expect)
+	}
+}
@@ -0,0 +1,43 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/snasphysicist/ferp/v2/pkg/configuration"
+)
+
+func TestIncomingWriteTimeoutOverridesServerTimeout(t *testing.T) {
+	m1 := mock{t: t, port: mockPorts()[0], routes: []route{
+		{path: "/test", method: http.MethodGet, rg: delayedResponse(300*time.Millisecond, http.StatusOK, "slow")},
+	}}
+	m2 := mock{t: t, port: mockPorts()[1], routes: []route{
+		{path: "/other/test", method: http.MethodGet, rg: delayedResponse(300*time.Millisecond, http.StatusOK, "slow")},
+	}}
+
+	port := randomPort()
+	f := startMocksAndProxyConfigured(t, []mock{m1, m2}, func(c *configuration.Configuration) {
+		s := serverNamed(t, c, configuration.ServerHTTP)
+		s.Port = port
+		s.Timeouts.Write = 100 * time.Millisecond
+		for i := range s.Incoming {
+			if s.Incoming[i].Path == "/test" {
+				s.Incoming[i].Timeouts.Write = 5 * time.Second
+			}
+		}
+	})
+	defer f()
+
+	sendRequestExpectResponse(t, requestResponse{
+		req: request{method: http.MethodGet, url: proxyURL(port, "test"), body: http.NoBody},
+		res: response{code: http.StatusOK, content: stringMatch{expect: "slow"}, headers: checkNoHeaders{}},
+	})
+
+	res, err := http.Get(proxyURL(port, "other/test"))
+	if err == nil {
+		_ = res.Body.Close()
+		t.Errorf("Got response %d from incoming without override, expected the server write timeout to cut it off",
+			res.StatusCode)
+	}
+}